@@ -3,58 +3,193 @@ package simplerr
 import (
 	"runtime"
 	"strings"
+	"sync"
 )
 
 const (
-	// maxStackFrames are the maximum number of stack frames returned with the error when using WithStackTrace
+	// maxStackFrames are the default maximum number of stack frames captured with the error
 	maxStackFrames = 16
 )
 
+// StackOptions configures how stack traces are captured when errors are created
+type StackOptions struct {
+	// Depth is the maximum number of stack frames captured. Zero or less uses the default depth of 16 frames.
+	Depth int
+	// Disabled turns off stack trace capture entirely
+	Disabled bool
+	// Keep, if set, decides whether an error keeps its stack trace once its code or benign flag is set.
+	// This allows dropping the stack traces of errors that do not need them, such as benign CodeNotFound errors.
+	Keep func(code Code, benign bool) bool
+	// WrapPointOnly captures only the frame at which an error is wrapped when the wrapped error already carries a
+	// stack trace. This avoids storing nearly identical stack traces for each layer of wrapping.
+	// Use `Stack()` to get the origin stack trace along with the locations at which it was wrapped.
+	WrapPointOnly bool
+}
+
+// StackCapture is the package-level configuration for capturing stack traces.
+// It can be overridden per registry by using `Registry.SetStackOptions()`.
+// StackCapture is read without synchronization each time an error is created, so it must only be set during program
+// startup, before any errors are created. Use `Registry.SetStackOptions()` to change the options while the program runs.
+var StackCapture StackOptions
+
+// symbolCache caches the symbolized calls for each program counter, stored as map[uintptr][]Call
+var symbolCache sync.Map
+
 // Call contains information for a specific call in the call stack
 type Call struct {
-	Line     int
-	File     string
-	Func     string
-	FuncName string
-	Package  string
+	Line     int    `json:"line"`
+	File     string `json:"file"`
+	Func     string `json:"func"`
+	FuncName string `json:"func_name"`
+	Package  string `json:"package"`
+}
+
+// stackOptions returns the stack options of the current registry, or the package-level options if it has none
+func stackOptions() *StackOptions {
+	if opts := GetRegistry().stackOptions(); opts != nil {
+		return opts
+	}
+	return &StackCapture
 }
 
-// stackTrace returns a more human readable form of the the stack trace from the slice of program counters
+// stackTrace returns a more human readable form of the the stack trace from the slice of program counters.
+// Symbolized calls are cached so that each program counter only needs to be symbolized once.
 func stackTrace(pcs []uintptr) []Call {
-	calls := make([]Call, 0, maxStackFrames)
+	calls := make([]Call, 0, len(pcs))
+	for _, pc := range pcs {
+		calls = append(calls, symbolize(pc)...)
+	}
+	return calls
+}
 
-	frames := runtime.CallersFrames(pcs)
+// symbolize returns the calls for a single program counter. A program counter can result in several calls when
+// functions have been inlined. The runtime.goexit frame at the bottom of every goroutine's stack is omitted.
+func symbolize(pc uintptr) []Call {
+	if cached, ok := symbolCache.Load(pc); ok {
+		return cached.([]Call) // nolint: errcheck
+	}
+
+	var calls []Call
+	frames := runtime.CallersFrames([]uintptr{pc})
 	for {
-		f, ok := frames.Next()
-		if !ok {
+		f, more := frames.Next()
+		if f.Function != "" && f.Function != "runtime.goexit" {
+			pkg, function := splitQualifiedFunctionName(f.Function)
+			calls = append(calls, Call{
+				Line:     f.Line,
+				File:     f.File,
+				FuncName: function,
+				Package:  pkg,
+				Func:     f.Function,
+			})
+		}
+		if !more {
 			break
 		}
+	}
+
+	symbolCache.Store(pc, calls)
+	return calls
+}
+
+// rawStackFrames extracts the slice of program counters associated with the stack trace and skips the first `skip`
+// number of calls. The depth of the stack trace and whether it is captured at all depends on the StackOptions.
+func rawStackFrames(skip int) []uintptr {
+	opts := stackOptions()
+	if opts.Disabled {
+		return nil
+	}
+
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = maxStackFrames
+	}
+
+	// Capture into a buffer on the stack and only allocate the frames that were actually captured
+	var buf [maxStackFrames]uintptr
+	pcs := buf[:]
+	if depth > len(buf) {
+		pcs = make([]uintptr, depth)
+	}
+	n := runtime.Callers(skip, pcs[:depth])
+	frames := make([]uintptr, n)
+	copy(frames, pcs)
+	return frames
+}
 
-		var pkg string
-		function := f.Function
+// wrapStackFrames extracts the program counters for an error that wraps err, skipping the first `skip` number of calls.
+// If the StackOptions are set to capture the wrap point only and err already carries a stack trace, only the frame
+// at which err is wrapped is captured.
+func wrapStackFrames(err error, skip int) []uintptr {
+	opts := stackOptions()
+	if opts.Disabled || !opts.WrapPointOnly || !hasStackTrace(err) {
+		return rawStackFrames(skip + 1)
+	}
 
-		if function != "" {
-			pkg, function = splitQualifiedFunctionName(function)
+	var pc [1]uintptr
+	n := runtime.Callers(skip, pc[:])
+	frames := make([]uintptr, n)
+	copy(frames, pc[:])
+	return frames
+}
+
+// hasStackTrace returns true if any error in the tree carries a stack trace
+func hasStackTrace(err error) bool {
+	type StackTracer interface {
+		StackTrace() []Call
+	}
+	return walk(err, func(e error) bool {
+		// Avoid symbolizing the stack trace of SimpleErrors
+		if serr, ok := e.(*SimpleError); ok {
+			return len(serr.rawStackFrames) > 0 || len(serr.stackTrace) > 0
 		}
+		stackTracer, ok := e.(StackTracer)
+		return ok && len(stackTracer.StackTrace()) > 0
+	})
+}
 
-		calls = append(calls, Call{
-			Line:     f.Line,
-			File:     f.File,
-			FuncName: function,
-			Package:  pkg,
-			Func:     runtime.FuncForPC(f.PC).Name(),
-		})
+// MergedStack is a single stack trace for an error tree, made up of the stack trace where the error originated and
+// the locations at which it was wrapped.
+type MergedStack struct {
+	// Origin is the stack trace of the deepest error in the tree that carries a stack trace
+	Origin []Call
+	// WrapPoints are the locations at which the error was wrapped, ordered from the outermost wrapper inwards
+	WrapPoints []Call
+}
 
+// Stack returns the merged stack trace of the error tree. The deepest error (in depth-first order) that carries a
+// stack trace is taken as the origin of the error, and every other error that carries a stack trace contributes the
+// location at which it was created (ie. where it wrapped the errors below it).
+func Stack(err error) MergedStack {
+	type StackTracer interface {
+		StackTrace() []Call
 	}
-	return calls
+	var stacks [][]Call
+	walk(err, func(e error) bool {
+		if stackTracer, ok := e.(StackTracer); ok {
+			if stack := stackTracer.StackTrace(); len(stack) > 0 {
+				stacks = append(stacks, stack)
+			}
+		}
+		return false
+	})
+
+	var merged MergedStack
+	if len(stacks) == 0 {
+		return merged
+	}
+	for _, stack := range stacks[:len(stacks)-1] {
+		merged.WrapPoints = append(merged.WrapPoints, stack[0])
+	}
+	merged.Origin = stacks[len(stacks)-1]
+	return merged
 }
 
-// rawStackFrames extracts the slice of program counters associated with the stack trace and skips the first `skip`
-// number of calls.
-func rawStackFrames(skip int) []uintptr {
-	pcs := make([]uintptr, maxStackFrames)
-	runtime.Callers(skip, pcs)
-	return pcs
+// applyStackPolicy drops the stack trace of the error if the StackOptions decide it should not be kept
+func (e *SimpleError) applyStackPolicy() {
+	if keep := stackOptions().Keep; keep != nil && !keep(e.code, e.benign) {
+		e.rawStackFrames = nil
+	}
 }
 
 // packageName returns the name of the package from the fully qualified package name
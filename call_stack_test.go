@@ -0,0 +1,292 @@
+package simplerr
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// newHelper is a helper constructor which should not show up in the stack trace
+func newHelper() *SimpleError {
+	return NewSkip(1, "created in helper")
+}
+
+// wrapHelper is a helper constructor which should not show up in the stack trace
+func wrapHelper(err error) *SimpleError {
+	return WrapSkip(err, 1)
+}
+
+func (s *TestSuite) TestStackOptions() {
+	defer func() { StackCapture = StackOptions{} }()
+
+	s.Run("default depth", func() {
+		frames := New("something").StackFrames()
+		s.NotEmpty(frames)
+		s.LessOrEqual(len(frames), maxStackFrames)
+		s.NotContains(frames, uintptr(0), "frames are trimmed")
+	})
+
+	s.Run("custom depth", func() {
+		StackCapture = StackOptions{Depth: 2}
+		s.Len(New("something").StackFrames(), 2)
+
+		StackCapture = StackOptions{Depth: maxStackFrames + 10}
+		s.Greater(len(Fourth().StackFrames()), 0)
+	})
+
+	s.Run("disabled", func() {
+		StackCapture = StackOptions{Disabled: true}
+		serr := New("something")
+		s.Nil(serr.StackFrames())
+		s.Empty(serr.StackTrace())
+		s.Nil(Wrap(serr).StackFrames())
+		s.Nil(Wrapf(serr, "wrapped").StackFrames())
+	})
+
+	s.Run("keep policy", func() {
+		StackCapture = StackOptions{Keep: func(code Code, benign bool) bool {
+			return !(code == CodeNotFound && benign)
+		}}
+		s.NotEmpty(New("something").Code(CodeNotFound).StackFrames())
+		s.Empty(New("something").Code(CodeNotFound).Benign().StackFrames())
+		s.Empty(New("something").BenignReason("expected").Code(CodeNotFound).StackFrames())
+		s.Empty(Define(CodeNotFound, "not found").Benign().New().StackFrames())
+		s.NotEmpty(New("something").Code(CodeUnavailable).Benign().StackFrames())
+	})
+
+	s.Run("registry stack options take precedence", func() {
+		StackCapture = StackOptions{Depth: 3}
+		r := NewRegistry()
+		r.SetStackOptions(&StackOptions{Disabled: true})
+		defaultRegistry := GetRegistry()
+		SetRegistry(r)
+		defer SetRegistry(defaultRegistry)
+
+		s.Nil(New("something").StackFrames())
+
+		r.SetStackOptions(nil)
+		s.Len(New("something").StackFrames(), 3)
+	})
+}
+
+func (s *TestSuite) TestSkipConstructors() {
+	serr := newHelper()
+	s.Equal("created in helper", serr.Error())
+	s.checkCall(serr.StackTrace()[0], "(*TestSuite).TestSkipConstructors")
+
+	wrapped := wrapHelper(serr)
+	s.Equal(serr, wrapped.Unwrap())
+	s.checkCall(wrapped.StackTrace()[0], "(*TestSuite).TestSkipConstructors")
+
+	s.checkCall(NewSkip(0, "no skip").StackTrace()[0], "(*TestSuite).TestSkipConstructors")
+	s.checkCall(WrapSkip(serr, 0).StackTrace()[0], "(*TestSuite).TestSkipConstructors")
+}
+
+func (s *TestSuite) TestStackTraceSymbolCache() {
+	serr := New("something")
+	first := serr.StackTrace()
+	s.Equal(first, serr.StackTrace(), "symbolized calls are cached")
+	for _, pc := range serr.StackFrames() {
+		_, ok := symbolCache.Load(pc)
+		s.True(ok)
+	}
+}
+
+// legacyRawStackFrames is the stack capture used before the StackOptions were introduced, which always allocates
+// maxStackFrames program counters. It is used to compare against the current capture.
+func legacyRawStackFrames(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackFrames)
+	runtime.Callers(skip, pcs)
+	return pcs
+}
+
+// legacyStackTrace is the symbolization used before symbolized calls were cached
+func legacyStackTrace(pcs []uintptr) []Call {
+	calls := make([]Call, 0, maxStackFrames)
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, ok := frames.Next()
+		if !ok {
+			break
+		}
+		pkg, function := splitQualifiedFunctionName(f.Function)
+		calls = append(calls, Call{
+			Line:     f.Line,
+			File:     f.File,
+			FuncName: function,
+			Package:  pkg,
+			Func:     runtime.FuncForPC(f.PC).Name(),
+		})
+	}
+	return calls
+}
+
+func (s *TestSuite) TestStackTraceMatchesLegacy() {
+	// Create the error on a new goroutine so that the stack is shallower than maxStackFrames
+	done := make(chan *SimpleError)
+	go func() {
+		done <- First()
+	}()
+	serr := (<-done).Unwrap().(*SimpleError).Unwrap().(*SimpleError).Unwrap().(*SimpleError) // nolint: errcheck
+
+	padded := make([]uintptr, maxStackFrames)
+	copy(padded, serr.StackFrames())
+	s.Equal(legacyStackTrace(padded), serr.StackTrace())
+
+	for _, call := range serr.StackTrace() {
+		s.NotEqual("runtime.goexit", call.Func)
+	}
+}
+
+// atStackDepth calls f from a call stack that is at least depth calls deep
+func atStackDepth(depth int, f func()) {
+	if depth <= 0 {
+		f()
+		return
+	}
+	atStackDepth(depth-1, f)
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	atStackDepth(2*maxStackFrames, func() {
+		for ii := 0; ii < b.N; ii++ {
+			_ = New("something")
+		}
+	})
+}
+
+func BenchmarkNewShallowStack(b *testing.B) {
+	defer func() { StackCapture = StackOptions{} }()
+	StackCapture = StackOptions{Depth: 4}
+	b.ReportAllocs()
+	atStackDepth(2*maxStackFrames, func() {
+		for ii := 0; ii < b.N; ii++ {
+			_ = New("something")
+		}
+	})
+}
+
+func BenchmarkNewWithoutStack(b *testing.B) {
+	defer func() { StackCapture = StackOptions{} }()
+	StackCapture = StackOptions{Disabled: true}
+	b.ReportAllocs()
+	for ii := 0; ii < b.N; ii++ {
+		_ = New("something")
+	}
+}
+
+func BenchmarkStackTrace(b *testing.B) {
+	serr := New("something")
+	b.ReportAllocs()
+	for ii := 0; ii < b.N; ii++ {
+		_ = serr.StackTrace()
+	}
+}
+
+func BenchmarkRawStackFrames(b *testing.B) {
+	b.ReportAllocs()
+	for ii := 0; ii < b.N; ii++ {
+		_ = rawStackFrames(1)
+	}
+}
+
+func BenchmarkLegacyRawStackFrames(b *testing.B) {
+	b.ReportAllocs()
+	for ii := 0; ii < b.N; ii++ {
+		_ = legacyRawStackFrames(1)
+	}
+}
+
+func BenchmarkLegacyStackTrace(b *testing.B) {
+	pcs := legacyRawStackFrames(1)
+	b.ReportAllocs()
+	for ii := 0; ii < b.N; ii++ {
+		_ = legacyStackTrace(pcs)
+	}
+}
+
+// stackTracerError is an error that is not a SimpleError but carries a stack trace
+type stackTracerError struct {
+	error
+	stack []Call
+}
+
+func (e stackTracerError) StackTrace() []Call {
+	return e.stack
+}
+
+func (s *TestSuite) TestWrapPointOnly() {
+	defer func() { StackCapture = StackOptions{} }()
+	StackCapture = StackOptions{WrapPointOnly: true}
+
+	s.Run("wrappers only capture the wrap point", func() {
+		e := First()
+		s.Len(e.StackFrames(), 1)
+		s.checkCall(e.StackTrace()[0], "First")
+
+		e = e.Unwrap().(*SimpleError) // nolint: errcheck
+		s.Len(e.StackFrames(), 1)
+		s.checkCall(e.StackTrace()[0], "Second")
+
+		e = e.Unwrap().(*SimpleError) // nolint: errcheck
+		s.Len(e.StackFrames(), 1)
+		s.checkCall(e.StackTrace()[0], "Third")
+
+		e = e.Unwrap().(*SimpleError) // nolint: errcheck
+		s.Greater(len(e.StackFrames()), 1, "the origin error captures the full stack trace")
+		s.checkCall(e.StackTrace()[0], "Fourth")
+	})
+
+	s.Run("wrapping errors without a stack trace captures the full stack trace", func() {
+		s.Greater(len(Wrap(fmt.Errorf("plain")).StackFrames()), 1)
+		s.Greater(len(Wrapf(fmt.Errorf("plain"), "wrapped").StackFrames()), 1)
+	})
+
+	s.Run("other error types with stack traces", func() {
+		err := stackTracerError{error: fmt.Errorf("plain"), stack: []Call{{Func: "origin"}}}
+		s.Len(Wrap(err).StackFrames(), 1)
+		s.Greater(len(Wrap(stackTracerError{error: fmt.Errorf("plain")}).StackFrames()), 1)
+	})
+
+	s.Run("joined and template errors", func() {
+		s.Len(Join(fmt.Errorf("plain"), New("something")).StackFrames(), 1)
+		s.Len(errTestTimeout.Wrap(New("something")).StackFrames(), 1)
+		s.Greater(len(errTestTimeout.New().StackFrames()), 1)
+	})
+
+	s.Run("disabled takes precedence", func() {
+		StackCapture = StackOptions{WrapPointOnly: true, Disabled: true}
+		defer func() { StackCapture = StackOptions{WrapPointOnly: true} }()
+		s.Nil(Wrap(New("something")).StackFrames())
+	})
+}
+
+func (s *TestSuite) TestMergedStack() {
+	for _, wrapPointOnly := range []bool{false, true} {
+		StackCapture = StackOptions{WrapPointOnly: wrapPointOnly}
+
+		e := fmt.Errorf("stdlib: %w", First())
+		stack := Stack(e)
+		s.Require().Len(stack.WrapPoints, 3)
+		s.checkCall(stack.WrapPoints[0], "First")
+		s.checkCall(stack.WrapPoints[1], "Second")
+		s.checkCall(stack.WrapPoints[2], "Third")
+		s.checkCall(stack.Origin[0], "Fourth")
+		s.checkCall(stack.Origin[1], "Third")
+		s.checkCall(stack.Origin[2], "Second")
+		s.checkCall(stack.Origin[3], "First")
+	}
+	StackCapture = StackOptions{}
+
+	s.Run("no stack traces", func() {
+		s.Equal(MergedStack{}, Stack(fmt.Errorf("plain")))
+		s.Equal(MergedStack{}, Stack(nil))
+	})
+
+	s.Run("single stack trace", func() {
+		stack := Stack(Fourth())
+		s.Empty(stack.WrapPoints)
+		s.checkCall(stack.Origin[0], "Fourth")
+	})
+}
@@ -0,0 +1,106 @@
+package simplerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// Classifier maps errors that do not have an error code, such as errors from the standard library, to an error code.
+// Classifiers are consulted by HasErrorCode(), HasErrorCodes() and the HTTP and gRPC translators when no error in the
+// tree has an explicit code, and by Wrap() to assign a code to the wrapping error.
+type Classifier interface {
+	// Classify returns the code of the error and true if the classifier recognizes the error
+	Classify(err error) (Code, bool)
+}
+
+// ClassifierFunc is a function that implements the Classifier interface
+type ClassifierFunc func(err error) (Code, bool)
+
+// Classify calls the function
+func (f ClassifierFunc) Classify(err error) (Code, bool) {
+	return f(err)
+}
+
+// ClassifyIs returns a Classifier that classifies errors that match the target, according to `errors.Is()`, with the code
+func ClassifyIs(target error, code Code) Classifier {
+	return ClassifierFunc(func(err error) (Code, bool) {
+		return code, errors.Is(err, target)
+	})
+}
+
+// ClassifyAs returns a Classifier that classifies errors of type T, according to `errors.As()`, with the code
+func ClassifyAs[T error](code Code) Classifier {
+	return ClassifierFunc(func(err error) (Code, bool) {
+		// Walk the tree with type assertions rather than calling errors.As(), which allocates the target on every call
+		return code, walk(err, func(e error) bool {
+			if _, ok := e.(T); ok {
+				return true
+			}
+			if x, ok := e.(interface{ As(any) bool }); ok {
+				var target T
+				return x.As(&target)
+			}
+			return false
+		})
+	})
+}
+
+// DefaultClassifiers returns the classifiers that every registry is initialized with. They classify errors from the
+// standard library:
+//
+//	context.Canceled                          CodeCanceled
+//	context.DeadlineExceeded                  CodeDeadlineExceeded
+//	os.ErrDeadlineExceeded                    CodeDeadlineExceeded
+//	fs.ErrNotExist (os.ErrNotExist)           CodeNotFound
+//	fs.ErrExist (os.ErrExist)                 CodeAlreadyExists
+//	fs.ErrPermission (os.ErrPermission)       CodePermissionDenied
+//	io.ErrUnexpectedEOF                       CodeMalformedRequest
+//	*json.SyntaxError                         CodeMalformedRequest
+//	*http.MaxBytesError                       CodeResourceExhausted
+//	errors.ErrUnsupported                     CodeNotSupported
+func DefaultClassifiers() []Classifier {
+	return []Classifier{
+		ClassifyIs(context.Canceled, CodeCanceled),
+		ClassifyIs(context.DeadlineExceeded, CodeDeadlineExceeded),
+		ClassifyIs(os.ErrDeadlineExceeded, CodeDeadlineExceeded),
+		ClassifyIs(fs.ErrNotExist, CodeNotFound),
+		ClassifyIs(fs.ErrExist, CodeAlreadyExists),
+		ClassifyIs(fs.ErrPermission, CodePermissionDenied),
+		ClassifyIs(io.ErrUnexpectedEOF, CodeMalformedRequest),
+		ClassifyAs[*json.SyntaxError](CodeMalformedRequest),
+		ClassifyAs[*http.MaxBytesError](CodeResourceExhausted),
+		ClassifyIs(errors.ErrUnsupported, CodeNotSupported),
+	}
+}
+
+// Classify classifies the error with the classifiers of the current registry. Classifiers registered later are
+// consulted first, and the code of the first classifier that recognizes the error is returned.
+// Note that Classify does not consider the explicit codes of SimpleErrors in the tree.
+func Classify(err error) (Code, bool) {
+	if err == nil {
+		return CodeUnknown, false
+	}
+	for _, c := range GetRegistry().load().classifiers {
+		if code, ok := c.Classify(err); ok {
+			return code, true
+		}
+	}
+	return CodeUnknown, false
+}
+
+// classify sets the code of a wrapping error to the classification of the wrapped error if no error in the tree has
+// an explicit code. The classifiers are not consulted when there is no wrapped error or the tree has an explicit code.
+func (e *SimpleError) classify() *SimpleError {
+	if e.parent == nil || explicitCodeOf(e.parent) != CodeUnknown {
+		return e
+	}
+	if code, ok := Classify(e.parent); ok {
+		e.setCode(code)
+	}
+	return e
+}
@@ -0,0 +1,135 @@
+package simplerr
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func (s *TestSuite) TestDefaultClassifiers() {
+	_, syntaxErr := json.Marshal(json.RawMessage("{"))
+	_, pathErr := os.Open("/does/not/exist")
+
+	testCases := []struct {
+		err      error
+		expected Code
+	}{
+		{context.Canceled, CodeCanceled},
+		{context.DeadlineExceeded, CodeDeadlineExceeded},
+		{os.ErrDeadlineExceeded, CodeDeadlineExceeded},
+		{os.ErrNotExist, CodeNotFound},
+		{pathErr, CodeNotFound},
+		{fs.ErrExist, CodeAlreadyExists},
+		{os.ErrPermission, CodePermissionDenied},
+		{io.ErrUnexpectedEOF, CodeMalformedRequest},
+		{syntaxErr, CodeMalformedRequest},
+		{&http.MaxBytesError{Limit: 10}, CodeResourceExhausted},
+		{errors.ErrUnsupported, CodeNotSupported},
+	}
+	for _, tc := range testCases {
+		wrapped := fmt.Errorf("wrapped: %w", tc.err)
+		code, ok := Classify(wrapped)
+		s.True(ok, tc.err.Error())
+		s.Equal(tc.expected, code, tc.err.Error())
+		s.True(HasErrorCode(wrapped, tc.expected), tc.err.Error())
+		s.Equal(tc.expected, Wrap(wrapped).GetCode(), tc.err.Error())
+	}
+
+	code, ok := Classify(fmt.Errorf("something"))
+	s.False(ok)
+	s.Equal(CodeUnknown, code)
+	_, ok = Classify(nil)
+	s.False(ok)
+
+	_, numErr := strconv.Atoi("one")
+	for _, err := range []error{sql.ErrNoRows, numErr} {
+		_, ok = Classify(err)
+		s.False(ok, "%v is only classified when opted in", err)
+	}
+}
+
+// asError is an error that can be converted to another error type with errors.As()
+type asError struct {
+	target *json.SyntaxError
+}
+
+func (e asError) Error() string {
+	return "as error"
+}
+
+func (e asError) As(target any) bool {
+	t, ok := target.(**json.SyntaxError)
+	if ok {
+		*t = e.target
+	}
+	return ok
+}
+
+func (s *TestSuite) TestClassifyAs() {
+	classifier := ClassifyAs[*json.SyntaxError](CodeMalformedRequest)
+
+	_, ok := classifier.Classify(fmt.Errorf("wrapped: %w", asError{target: &json.SyntaxError{}}))
+	s.True(ok, "errors that convert themselves with an As method are classified")
+	_, ok = classifier.Classify(errors.Join(io.EOF, &json.SyntaxError{}))
+	s.True(ok, "joined errors are classified")
+	_, ok = classifier.Classify(asError{})
+	s.True(ok)
+	_, ok = classifier.Classify(&http.MaxBytesError{})
+	s.False(ok)
+	_, ok = ClassifyAs[*http.MaxBytesError](CodeResourceExhausted).Classify(asError{})
+	s.False(ok, "the As method is consulted for the classified type only")
+}
+
+func BenchmarkWrapClassify(b *testing.B) {
+	err := fmt.Errorf("wrapped: %w", errors.New("unclassified"))
+	b.ReportAllocs()
+	for ii := 0; ii < b.N; ii++ {
+		_ = Wrap(err)
+	}
+}
+
+func (s *TestSuite) TestCustomClassifiers() {
+	errLegacyMissing := errors.New("legacy: record missing")
+
+	r := NewRegistry()
+	s.Len(r.Classifiers(), len(DefaultClassifiers()), "new registries have the default classifiers")
+	r.RegisterClassifier(ClassifyIs(errLegacyMissing, CodeNotFound))
+	r.RegisterClassifier(ClassifierFunc(func(err error) (Code, bool) {
+		// Registered later, so it takes precedence over the classifier above
+		return CodeUnavailable, err.Error() == "legacy: record missing"
+	}))
+	defaultRegistry := GetRegistry()
+	SetRegistry(r)
+	defer SetRegistry(defaultRegistry)
+
+	s.Len(r.Classifiers(), len(DefaultClassifiers())+2)
+	s.True(HasErrorCode(errLegacyMissing, CodeUnavailable))
+	s.True(HasErrorCode(fmt.Errorf("wrapped: %w", errLegacyMissing), CodeNotFound))
+	s.True(HasErrorCode(context.Canceled, CodeCanceled), "the custom registry classifies context errors")
+	s.Equal(CodeCanceled, Wrap(context.Canceled).GetCode())
+
+	s.Run("explicit codes take precedence", func() {
+		err := Wrap(errLegacyMissing).Code(CodePermissionDenied)
+		s.False(HasErrorCode(err, CodeNotFound))
+		s.Equal(CodeUnknown, Wrap(err).GetCode(), "wrapping an error with a code does not classify it")
+	})
+
+	s.Run("frozen registry", func() {
+		r.Freeze()
+		err := r.TryRegisterClassifier(ClassifyIs(io.EOF, CodeNotFound))
+		s.ErrorIs(err, ErrRegistryFrozen)
+		s.EqualError(err, "cannot register classifier: registry is frozen")
+		s.Panics(func() {
+			r.RegisterClassifier(ClassifyIs(io.EOF, CodeNotFound))
+		})
+		s.Len(r.Classifiers(), len(DefaultClassifiers())+2)
+	})
+}
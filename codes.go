@@ -1,5 +1,11 @@
 package simplerr
 
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
 // Code is an error code that indicates the category of error
 type Code int
 
@@ -38,25 +44,83 @@ const (
 	CodeResourceExhausted Code = 13
 	// CodeUnavailable indicates that the server itself is unavailable for processing requests.
 	CodeUnavailable Code = 14
+	// CodeInternal indicates that an invariant of the system has been broken, such as a recovered panic
+	CodeInternal Code = 15
 )
 
 // NumberOfReservedCodes is the code number, under which, are reserved for use by this library.
 const NumberOfReservedCodes = 100
 
-var defaultErrorCodes = map[Code]string{
-	CodeUnknown:            "unknown",
-	CodeAlreadyExists:      "already exists",
-	CodeNotFound:           "not found",
-	CodeInvalidArgument:    "invalid argument",
-	CodeMalformedRequest:   "malformed request",
-	CodeUnauthenticated:    "unauthenticated",
-	CodePermissionDenied:   "permission denied",
-	CodeConstraintViolated: "constraint violated",
-	CodeNotSupported:       "not supported",
-	CodeMissingParameter:   "parameter is missing",
-	CodeNotImplemented:     "not implemented",
-	CodeDeadlineExceeded:   "deadline exceeded",
-	CodeCanceled:           "canceled",
-	CodeResourceExhausted:  "resource exhausted",
-	CodeUnavailable:        "unavailable",
+var defaultErrorCodes = map[Code]CodeMetadata{
+	CodeUnknown:            {Name: "UNKNOWN", Description: "unknown"},
+	CodeAlreadyExists:      {Name: "ALREADY_EXISTS", Description: "already exists"},
+	CodeNotFound:           {Name: "NOT_FOUND", Description: "not found"},
+	CodeInvalidArgument:    {Name: "INVALID_ARGUMENT", Description: "invalid argument"},
+	CodeMalformedRequest:   {Name: "MALFORMED_REQUEST", Description: "malformed request"},
+	CodeUnauthenticated:    {Name: "UNAUTHENTICATED", Description: "unauthenticated"},
+	CodePermissionDenied:   {Name: "PERMISSION_DENIED", Description: "permission denied"},
+	CodeConstraintViolated: {Name: "CONSTRAINT_VIOLATED", Description: "constraint violated"},
+	CodeNotSupported:       {Name: "NOT_SUPPORTED", Description: "not supported"},
+	CodeMissingParameter:   {Name: "MISSING_PARAMETER", Description: "parameter is missing"},
+	CodeNotImplemented:     {Name: "NOT_IMPLEMENTED", Description: "not implemented"},
+	CodeDeadlineExceeded:   {Name: "DEADLINE_EXCEEDED", Description: "deadline exceeded"},
+	CodeCanceled:           {Name: "CANCELED", Description: "canceled"},
+	CodeResourceExhausted:  {Name: "RESOURCE_EXHAUSTED", Description: "resource exhausted"},
+	CodeUnavailable:        {Name: "UNAVAILABLE", Description: "unavailable"},
+	CodeInternal:           {Name: "INTERNAL", Description: "internal error"},
+}
+
+// String returns the name of the code as registered in the current registry.
+// Codes that are not registered with a name are rendered as their number.
+func (c Code) String() string {
+	if name := GetRegistry().CodeName(c); name != "" {
+		return name
+	}
+	return strconv.Itoa(int(c))
+}
+
+// MarshalText implements the `encoding.TextMarshaler` interface so that codes are encoded by their name in formats
+// such as JSON and YAML. Codes that are not registered with a name are encoded as their number.
+func (c Code) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements the `encoding.TextUnmarshaler` interface. See `ParseCode()`.
+func (c *Code) UnmarshalText(text []byte) error {
+	code, err := ParseCode(string(text))
+	if err != nil {
+		return err
+	}
+	*c = code
+	return nil
+}
+
+// UnmarshalJSON implements the `json.Unmarshaler` interface. Codes are decoded from their name or from their number,
+// either as a JSON number or as a string, so that JSON encoded before codes were written by name can still be decoded.
+func (c *Code) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*c = Code(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return c.UnmarshalText([]byte(s))
+}
+
+// ParseCode parses a code from its name, as registered in the current registry, or from its number.
+func ParseCode(s string) (Code, error) {
+	if code, ok := GetRegistry().Lookup(s); ok {
+		return code, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return CodeUnknown, New("unknown error code %q", s).Code(CodeInvalidArgument)
+	}
+	return Code(n), nil
 }
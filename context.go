@@ -0,0 +1,38 @@
+package simplerr
+
+import (
+	"context"
+	"errors"
+)
+
+// FromContext returns a SimpleError describing why the context is done, or nil if the context is not done.
+// The error has CodeCanceled or CodeDeadlineExceeded and wraps the cause of the context (see `context.Cause()`).
+// If the cause is a SimpleError with a code, its code and auxiliary data carry through instead.
+//
+//	select {
+//	case <-ctx.Done():
+//		return simplerr.FromContext(ctx)
+//	case v := <-results:
+//		...
+//	}
+func FromContext(ctx context.Context) *SimpleError {
+	ctxErr := ctx.Err()
+	if ctxErr == nil {
+		return nil
+	}
+
+	cause := context.Cause(ctx)
+	e := &SimpleError{parent: cause, rawStackFrames: wrapStackFrames(cause, 3)}
+	// Keep the reason the context is done in the error string when a custom cause was given
+	if cause != ctxErr {
+		e.msg = ctxErr.Error()
+	}
+	if explicitCodeOf(cause) == CodeUnknown {
+		code := CodeDeadlineExceeded
+		if errors.Is(ctxErr, context.Canceled) {
+			code = CodeCanceled
+		}
+		e.setCode(code)
+	}
+	return e
+}
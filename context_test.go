@@ -0,0 +1,90 @@
+package simplerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *TestSuite) TestFromContext() {
+	s.Run("context not done", func() {
+		s.Nil(FromContext(context.Background()))
+	})
+
+	s.Run("canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		serr := FromContext(ctx)
+		s.Equal(CodeCanceled, serr.GetCode())
+		s.Equal("context canceled", serr.Error())
+		s.ErrorIs(serr, context.Canceled)
+		s.Contains(serr.StackTrace()[0].Func, "TestFromContext")
+	})
+
+	s.Run("deadline exceeded", func() {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		serr := FromContext(ctx)
+		s.Equal(CodeDeadlineExceeded, serr.GetCode())
+		s.ErrorIs(serr, context.DeadlineExceeded)
+	})
+
+	s.Run("plain cause", func() {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cause := fmt.Errorf("shutting down")
+		cancel(cause)
+		serr := FromContext(ctx)
+		s.Equal(CodeCanceled, serr.GetCode())
+		s.Equal("context canceled: shutting down", serr.Error())
+		s.ErrorIs(serr, cause)
+	})
+
+	s.Run("SimpleError cause", func() {
+		ctx, cancel := context.WithTimeoutCause(context.Background(), 0, New("upstream too slow").Code(CodeUnavailable).Aux("upstream", "billing"))
+		defer cancel()
+		serr := FromContext(ctx)
+		s.Equal(CodeUnknown, serr.GetCode())
+		s.True(HasErrorCode(serr, CodeUnavailable))
+		s.False(HasErrorCode(serr, CodeDeadlineExceeded), "the code of the cause takes precedence")
+		s.Equal(map[string]interface{}{"upstream": "billing"}, ExtractAuxiliary(serr))
+		s.Equal("context deadline exceeded: upstream too slow", serr.Error())
+	})
+}
+
+func (s *TestSuite) TestContextClassification() {
+	s.Run("wrap classifies context errors", func() {
+		s.Equal(CodeCanceled, Wrap(context.Canceled).GetCode())
+		s.Equal(CodeDeadlineExceeded, Wrapf(context.DeadlineExceeded, "querying").GetCode())
+		s.Equal(CodeDeadlineExceeded, WrapSkip(fmt.Errorf("query: %w", context.DeadlineExceeded), 0).GetCode())
+		s.Equal(CodeUnknown, Wrap(fmt.Errorf("something")).GetCode())
+		s.Equal(CodeUnknown, Wrap(nil).GetCode())
+	})
+
+	s.Run("wrap does not override existing codes", func() {
+		inner := Wrap(context.Canceled).Code(CodeUnavailable)
+		outer := Wrap(inner)
+		s.Equal(CodeUnknown, outer.GetCode())
+		s.True(HasErrorCode(outer, CodeUnavailable))
+	})
+
+	s.Run("context errors without a code are detected", func() {
+		s.True(HasErrorCode(context.Canceled, CodeCanceled))
+		s.True(HasErrorCode(fmt.Errorf("query: %w", context.DeadlineExceeded), CodeDeadlineExceeded))
+		s.False(HasErrorCode(context.Canceled, CodeDeadlineExceeded))
+
+		code, ok := HasErrorCodes(errors.Join(fmt.Errorf("other"), context.DeadlineExceeded), CodeNotFound, CodeDeadlineExceeded)
+		s.True(ok)
+		s.Equal(CodeDeadlineExceeded, code)
+
+		_, ok = HasErrorCodes(fmt.Errorf("wrapped: %w", New("something").Code(CodeNotFound)), CodeCanceled)
+		s.False(ok)
+		s.False(HasErrorCode(fmt.Errorf("something"), CodeCanceled))
+	})
+
+	s.Run("explicit codes take precedence over context errors", func() {
+		err := errors.Join(context.Canceled, New("something").Code(CodeNotFound))
+		s.False(HasErrorCode(err, CodeCanceled))
+		s.True(HasErrorCode(err, CodeNotFound))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"io"
 )
 
 type attr int
@@ -18,7 +19,10 @@ const (
 )
 
 // ReturnSimpleErrors returns a unary client interceptor that converts errors returned by the client to simplerr compatible
-// errors. The underlying grpc status and code can still be extracted using the same status.FromError() and status.Code() methods
+// errors. The underlying grpc status and code can still be extracted using the same status.FromError() and status.Code() methods.
+// The message of the error is the message of the status. The exact simplerr code, flags, retry-after hint, field
+// violations and auxiliary data sent in the status details by TranslateErrorCode are restored on the error. If the
+// status has no such details, the code is mapped from the gRPC code with the registry's inverse mapping.
 func ReturnSimpleErrors(registry *Registry) grpc.UnaryClientInterceptor {
 
 	if registry == nil {
@@ -33,25 +37,110 @@ func ReturnSimpleErrors(registry *Registry) grpc.UnaryClientInterceptor {
 			return nil
 		}
 
-		grpcCode := codes.Unknown
+		return convertError(err, method, registry, 1)
+	}
+}
 
-		serr := simplerr.Wrap(err).Attr(AttrGRPCMethod, method) // nolint: govet
+// ReturnSimpleErrorsStream is the stream client interceptor equivalent of ReturnSimpleErrors. It converts errors
+// returned when creating the stream, as well as errors returned by the `RecvMsg()` and `SendMsg()` methods of the
+// stream, to simplerr compatible errors in the same way. `io.EOF`, which signals the end of the stream, is returned as-is.
+func ReturnSimpleErrorsStream(registry *Registry) grpc.StreamClientInterceptor {
 
-		// Check if the error is a gRPC status error
-		// The GRPC framework seems to always return grpc errors on the client side, even if the server does not
-		// Therefore, this block should always run
-		if st, ok := status.FromError(err); ok {
-			_ = serr.Attr(AttrGRPCStatus, st)
+	if registry == nil {
+		registry = defaultRegistry
+	}
 
-			grpcCode = st.Code()
-			simplerrCode, _ := registry.getGRPCCode(grpcCode)
-			_ = serr.Code(simplerrCode)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, convertError(err, method, registry, 1)
 		}
+		return &clientStream{ClientStream: stream, method: method, registry: registry}, nil
+	}
+}
 
-		return &grpcError{
-			SimpleError: serr,
-			code:        grpcCode,
+// Retry returns a unary client interceptor that retries calls according to the policy with `simplerr.Retry()`. Calls
+// are retried if the returned error is retriable or has one of the policy's RetryCodes, waiting for the retry delay
+// sent by the server in a `google.rpc.RetryInfo` detail or otherwise the policy's jittered backoff. No attempt is made
+// past the call's deadline. The returned error wraps the error of the last attempt and records the number of
+// attempts, which can be retrieved with `simplerr.GetRetryHistory()`.
+//
+// Retry must be chained before ReturnSimpleErrors so that it can inspect the simplerr code and flags of each attempt,
+// and so that the returned error still carries the AttrGRPCMethod and AttrGRPCStatus attributes:
+//
+//	grpc.WithChainUnaryInterceptor(simplegrpc.Retry(simplerr.DefaultRetryPolicy()), simplegrpc.ReturnSimpleErrors(nil))
+func Retry(policy simplerr.RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return simplerr.Retry(ctx, policy, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// RetryStream is the stream client interceptor equivalent of Retry. Only errors returned when creating the stream
+// are retried, because messages that were already sent or received on a stream cannot be replayed. It must be chained
+// before ReturnSimpleErrorsStream.
+func RetryStream(policy simplerr.RetryPolicy) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		err := simplerr.Retry(ctx, policy, func(ctx context.Context) error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		})
+		if err != nil {
+			return nil, err
 		}
+		return stream, nil
+	}
+}
+
+// clientStream wraps a grpc.ClientStream to convert the errors returned by the stream to simplerr compatible errors
+type clientStream struct {
+	grpc.ClientStream
+	method   string
+	registry *Registry
+}
+
+// SendMsg calls the underlying stream's SendMsg and converts the returned error
+func (s *clientStream) SendMsg(m interface{}) error {
+	return s.convert(s.ClientStream.SendMsg(m))
+}
+
+// RecvMsg calls the underlying stream's RecvMsg and converts the returned error
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return s.convert(s.ClientStream.RecvMsg(m))
+}
+
+// convert converts errors returned by the stream, except for io.EOF which signals the end of the stream
+func (s *clientStream) convert(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	// Skip convert and the SendMsg or RecvMsg method so that the stack trace starts at their caller
+	return convertError(err, s.method, s.registry, 2)
+}
+
+// convertError converts an error returned by a gRPC client to a simplerr compatible error which wraps it.
+// The stack trace of the error skips `skip` calls above convertError, so that it starts at the caller of the interceptor.
+func convertError(err error, method string, registry *Registry, skip int) error {
+	grpcCode := codes.Unknown
+
+	var serr *simplerr.SimpleError
+	// Check if the error is a gRPC status error
+	// The GRPC framework seems to always return grpc errors on the client side, even if the server does not
+	// Therefore, this branch should always run
+	if st, ok := status.FromError(err); ok {
+		serr = errorFromStatus(err, st, registry, skip+1).Attr(AttrGRPCStatus, st)
+		grpcCode = st.Code()
+	} else {
+		serr = simplerr.WrapSkip(err, skip+1)
+	}
+	_ = serr.Attr(AttrGRPCMethod, method)
 
+	return &grpcError{
+		SimpleError: serr,
+		code:        grpcCode,
+		msg:         err.Error(),
 	}
 }
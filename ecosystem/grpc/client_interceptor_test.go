@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/lobocv/simplerr"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -66,6 +67,30 @@ func TestClientInterceptorNotGPRCError(t *testing.T) {
 
 }
 
+func TestClientInterceptorFieldViolations(t *testing.T) {
+	// Send the error through the server interceptor to build the status that the client receives
+	serverErr := simplerr.Invalid().Field("email", "must be a valid address").Field("age", "must be at least 18")
+	_, err := TranslateErrorCode(nil)(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, serverErr
+	})
+	st, err := status.Convert(err).WithDetails(&errdetails.RetryInfo{})
+	require.NoError(t, err)
+
+	err = makeMockGrpcCall(st.Err())()
+	require.True(t, simplerr.HasErrorCode(err, simplerr.CodeInvalidArgument))
+	require.Equal(t, serverErr.GetFieldViolations(), simplerr.GetFieldViolations(err))
+}
+
+func TestClientInterceptorWrapsError(t *testing.T) {
+	original := status.Error(codes.NotFound, "not found")
+	err := ReturnSimpleErrors(nil)(context.Background(), "/ping.PingService/Ping", nil, nil, nil, mockInvoker(original))
+
+	require.Equal(t, "not found", err.Error())
+	require.ErrorIs(t, err, original, "the error returned by the client is kept in the chain")
+	require.Equal(t, "TestClientInterceptorWrapsError", simplerr.As(err).StackTrace()[0].FuncName,
+		"the stack trace starts at the caller of the interceptor")
+}
+
 func TestClientInterceptorNoError(t *testing.T) {
 	err := makeMockGrpcCall(nil)()
 	require.Nil(t, err)
@@ -29,6 +29,7 @@ func DefaultMapping() map[simplerr.Code]codes.Code {
 		simplerr.CodeInvalidArgument:   codes.InvalidArgument,
 		simplerr.CodeResourceExhausted: codes.ResourceExhausted,
 		simplerr.CodeUnavailable:       codes.Unavailable,
+		simplerr.CodeInternal:          codes.Internal,
 	}
 
 	return m
@@ -48,6 +49,7 @@ func DefaultInverseMapping() map[codes.Code]simplerr.Code {
 		codes.InvalidArgument:   simplerr.CodeInvalidArgument,
 		codes.ResourceExhausted: simplerr.CodeResourceExhausted,
 		codes.Unavailable:       simplerr.CodeUnavailable,
+		codes.Internal:          simplerr.CodeInternal,
 	}
 
 	return m
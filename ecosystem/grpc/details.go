@@ -0,0 +1,173 @@
+package simplegrpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lobocv/simplerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorInfoDomain is the domain of the `google.rpc.ErrorInfo` status details sent by TranslateErrorCode. Clients using
+// ReturnSimpleErrors only rebuild errors from ErrorInfo details with this domain.
+const ErrorInfoDomain = "simplerr"
+
+const (
+	// metadataCode is the ErrorInfo metadata key for the number of the simplerr code
+	metadataCode = "code"
+	// metadataBenign is the ErrorInfo metadata key for the benign flag
+	metadataBenign = "benign"
+	// metadataBenignReason is the ErrorInfo metadata key for the reason the error is benign
+	metadataBenignReason = "benign_reason"
+	// metadataRetriable is the ErrorInfo metadata key for the retriable flag
+	metadataRetriable = "retriable"
+	// metadataAuxPrefix is the prefix of the ErrorInfo metadata keys for auxiliary data
+	metadataAuxPrefix = "aux_"
+)
+
+// statusDetails builds the details that are sent in the status of the error:
+//
+//   - `google.rpc.ErrorInfo` with the exact simplerr code, its flags and the auxiliary data selected with
+//     `Registry.SetDetailAux()`
+//   - `google.rpc.RetryInfo` if the error has a retry-after hint
+//   - `google.rpc.BadRequest` if the error has field violations
+//   - `google.rpc.Help` with a link to the documentation of the code if it is registered with a `DocURL`
+//   - `google.rpc.DebugInfo` with the full error string and stack trace if enabled with `Registry.SetDebugInfo()`
+func statusDetails(err error, registry *Registry) []protoadapt.MessageV1 {
+	code := simplerr.GetCode(err)
+	// The reason must be in UPPER_SNAKE_CASE, so codes without a name are sent as CODE_<number>
+	reason := simplerr.GetRegistry().CodeName(code)
+	if reason == "" {
+		reason = "CODE_" + strconv.Itoa(int(code))
+	}
+	errorInfo := &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorInfoDomain,
+		Metadata: map[string]string{metadataCode: strconv.Itoa(int(code))},
+	}
+	if reason, benign := simplerr.IsBenign(err); benign {
+		errorInfo.Metadata[metadataBenign] = "true"
+		if reason != "" {
+			errorInfo.Metadata[metadataBenignReason] = reason
+		}
+	}
+	if simplerr.IsRetriable(err) {
+		errorInfo.Metadata[metadataRetriable] = "true"
+	}
+	aux := simplerr.ExtractAuxiliary(err)
+	for _, k := range registry.detailAux {
+		if v, ok := aux[k]; ok {
+			errorInfo.Metadata[metadataAuxPrefix+k] = fmt.Sprint(v)
+		}
+	}
+	details := []protoadapt.MessageV1{errorInfo}
+
+	if retryAfter, ok := simplerr.GetRetryAfter(err); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+
+	if violations := simplerr.GetFieldViolations(err); len(violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	if docURL := simplerr.GetRegistry().CodeDocURL(code); docURL != "" {
+		details = append(details, &errdetails.Help{Links: []*errdetails.Help_Link{{
+			Description: simplerr.GetRegistry().CodeDescription(code),
+			Url:         docURL,
+		}}})
+	}
+
+	if registry.debugInfo {
+		debugInfo := &errdetails.DebugInfo{Detail: err.Error()}
+		if serr := simplerr.As(err); serr != nil {
+			for _, call := range serr.StackTrace() {
+				debugInfo.StackEntries = append(debugInfo.StackEntries, fmt.Sprintf("%s:%d %s", call.File, call.Line, call.Func))
+			}
+		}
+		details = append(details, debugInfo)
+	}
+
+	return details
+}
+
+// statusError is an error returned by a gRPC client whose error string is the message of its status, without the
+// "rpc error: code = ... desc = ..." decoration
+type statusError struct {
+	err error
+	msg string
+}
+
+// Error returns the message of the status
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the error returned by the gRPC client
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// errorFromStatus rebuilds a SimpleError from the status of an error received by a client. The SimpleError wraps the
+// error and its message is the message of the status. The code is taken from the ErrorInfo detail sent by
+// TranslateErrorCode, preferring the name of the code over its number, and otherwise from the registry's inverse
+// mapping of the status code. The stack trace skips `skip` calls above errorFromStatus.
+func errorFromStatus(err error, st *status.Status, registry *Registry, skip int) *simplerr.SimpleError {
+	serr := simplerr.WrapSkip(&statusError{err: err, msg: st.Message()}, skip+1)
+	code, _ := registry.getGRPCCode(st.Code())
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != ErrorInfoDomain {
+				continue
+			}
+			code = errorInfoCode(d, code)
+			metadata := d.GetMetadata()
+			if metadata[metadataBenign] == "true" {
+				_ = serr.BenignReason(metadata[metadataBenignReason])
+			}
+			if metadata[metadataRetriable] == "true" {
+				_ = serr.Retriable()
+			}
+			for k, v := range metadata {
+				if auxKey, ok := strings.CutPrefix(k, metadataAuxPrefix); ok {
+					_ = serr.Aux(auxKey, v)
+				}
+			}
+		case *errdetails.RetryInfo:
+			_ = serr.RetryAfter(d.GetRetryDelay().AsDuration())
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				_ = serr.Field(v.GetField(), v.GetDescription())
+			}
+		case *errdetails.DebugInfo:
+			_ = serr.Aux("debug_detail", d.GetDetail(), "debug_stack", d.GetStackEntries())
+		}
+	}
+
+	// Set the code last so that it is not overridden by the field violations
+	return serr.Code(code)
+}
+
+// errorInfoCode returns the simplerr code in the ErrorInfo detail. The name of the code is preferred because it is
+// stable across services, otherwise its number is used. If neither can be used, the fallback code is returned.
+func errorInfoCode(errorInfo *errdetails.ErrorInfo, fallback simplerr.Code) simplerr.Code {
+	if code, ok := simplerr.GetRegistry().Lookup(errorInfo.GetReason()); ok {
+		return code
+	}
+	if n, err := strconv.Atoi(errorInfo.GetMetadata()[metadataCode]); err == nil {
+		return simplerr.Code(n)
+	}
+	return fallback
+}
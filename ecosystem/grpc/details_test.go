@@ -0,0 +1,158 @@
+package simplegrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lobocv/simplerr"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sendError sends the error through the server interceptor and returns the error returned by the client interceptor
+func sendError(t *testing.T, serverRegistry *Registry, err error) error {
+	_, serverErr := TranslateErrorCode(serverRegistry)(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, err
+	})
+	// Rebuild the status from its proto to simulate sending it over the wire
+	st, ok := status.FromError(serverErr)
+	require.True(t, ok)
+	return makeMockGrpcCall(status.FromProto(st.Proto()).Err())()
+}
+
+func TestStatusDetailsRoundTrip(t *testing.T) {
+	r := simplerr.NewRegistry()
+	r.RegisterErrorCode(1100, "order rejected")
+	r.RegisterCode(1101, simplerr.CodeMetadata{Name: "ORDER_EXPIRED", Description: "order expired"})
+	r.RegisterCode(1102, simplerr.CodeMetadata{Description: "order locked", DocURL: "https://example.com/errors/order-locked"})
+	defaultRegistry := simplerr.GetRegistry()
+	simplerr.SetRegistry(r)
+	defer simplerr.SetRegistry(defaultRegistry)
+
+	serverRegistry := NewRegistry()
+	serverRegistry.SetDetailAux("order_id", "missing")
+
+	t.Run("code, message and flags", func(t *testing.T) {
+		err := sendError(t, serverRegistry, simplerr.New("order 42 expired at 10:00").
+			Code(1101).
+			Public("order expired").
+			BenignReason("expected").
+			RetryAfter(3*time.Second).
+			Aux("order_id", 42, "secret", "internal"))
+
+		require.Equal(t, "order expired", err.Error(), "the message is not prefixed with the gRPC code")
+		require.Equal(t, simplerr.Code(1101), simplerr.GetCode(err), "custom codes survive the hop")
+		require.Equal(t, codes.Unknown, status.Code(err))
+		reason, benign := simplerr.IsBenign(err)
+		require.True(t, benign)
+		require.Equal(t, "expected", reason)
+		require.True(t, simplerr.IsRetriable(err))
+		retryAfter, ok := simplerr.GetRetryAfter(err)
+		require.True(t, ok)
+		require.Equal(t, 3*time.Second, retryAfter)
+		require.Equal(t, map[string]interface{}{"order_id": "42"}, simplerr.ExtractAuxiliary(err), "only the selected aux is sent")
+	})
+
+	t.Run("unnamed codes are sent by number", func(t *testing.T) {
+		err := sendError(t, serverRegistry, simplerr.New("rejected").Code(1100).Retriable())
+		require.Equal(t, simplerr.Code(1100), simplerr.GetCode(err))
+		errorInfo, ok := status.Convert(err).Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, "CODE_1100", errorInfo.GetReason(), "reasons are in UPPER_SNAKE_CASE")
+		require.True(t, simplerr.IsRetriable(err))
+		_, benign := simplerr.IsBenign(err)
+		require.False(t, benign)
+	})
+
+	t.Run("doc url is sent as help", func(t *testing.T) {
+		err := sendError(t, serverRegistry, simplerr.New("order 42 is locked").Code(1102))
+		require.Equal(t, simplerr.Code(1102), simplerr.GetCode(err))
+		details := status.Convert(err).Details()
+		require.Len(t, details, 2)
+		help, ok := details[1].(*errdetails.Help)
+		require.True(t, ok)
+		require.Len(t, help.GetLinks(), 1)
+		require.Equal(t, "order locked", help.GetLinks()[0].GetDescription())
+		require.Equal(t, "https://example.com/errors/order-locked", help.GetLinks()[0].GetUrl())
+	})
+
+	t.Run("mapped codes", func(t *testing.T) {
+		err := sendError(t, nil, simplerr.New("user 1 not found").Code(simplerr.CodeNotFound).Benign())
+		require.Equal(t, simplerr.CodeNotFound, simplerr.GetCode(err))
+		require.Equal(t, codes.NotFound, status.Code(err))
+		reason, benign := simplerr.IsBenign(err)
+		require.True(t, benign)
+		require.Empty(t, reason)
+		require.Empty(t, simplerr.ExtractAuxiliary(err))
+	})
+}
+
+func TestStatusDetailsTwoHops(t *testing.T) {
+	// The downstream service fails and the middle-tier service receives its error
+	clientErr := sendError(t, nil, simplerr.New("connection refused").Code(simplerr.CodeUnavailable).Public("inventory unavailable"))
+	require.Equal(t, codes.Unavailable, status.Code(clientErr))
+
+	t.Run("client error returned as is", func(t *testing.T) {
+		err := sendError(t, nil, clientErr)
+		require.Equal(t, codes.Unavailable, status.Code(err), "the downstream status is forwarded")
+		require.Equal(t, "inventory unavailable", err.Error())
+		require.Equal(t, simplerr.CodeUnavailable, simplerr.GetCode(err))
+	})
+
+	t.Run("client error wrapped by the handler", func(t *testing.T) {
+		err := sendError(t, nil, simplerr.Wrapf(clientErr, "get order 42").Code(simplerr.CodeNotFound).Public("order not found"))
+		require.Equal(t, codes.NotFound, status.Code(err), "the wrapping error's code is sent")
+		require.Equal(t, "order not found", err.Error(), "the wrapping error's public message is sent")
+		require.Equal(t, simplerr.CodeNotFound, simplerr.GetCode(err))
+		errorInfo, ok := status.Convert(err).Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, "NOT_FOUND", errorInfo.GetReason(), "the wrapping error's details are sent")
+	})
+}
+
+func TestStatusDetailsDebugInfo(t *testing.T) {
+	serverRegistry := NewRegistry()
+	serverRegistry.SetDebugInfo(true)
+
+	err := sendError(t, serverRegistry, simplerr.New("dial tcp 10.0.0.1:5432").Code(simplerr.CodeUnavailable))
+	require.Equal(t, "unavailable", err.Error())
+	aux := simplerr.ExtractAuxiliary(err)
+	require.Equal(t, "dial tcp 10.0.0.1:5432", aux["debug_detail"])
+	require.NotEmpty(t, aux["debug_stack"])
+	require.Contains(t, aux["debug_stack"].([]string)[0], "TestStatusDetailsDebugInfo") // nolint: errcheck
+
+	// Errors that are not SimpleErrors have no stack trace
+	err = sendError(t, serverRegistry, context.Canceled)
+	require.Equal(t, "context canceled", simplerr.ExtractAuxiliary(err)["debug_detail"])
+	require.Empty(t, simplerr.ExtractAuxiliary(err)["debug_stack"])
+}
+
+func TestStatusDetailsFallback(t *testing.T) {
+	testCases := []struct {
+		name      string
+		errorInfo *errdetails.ErrorInfo
+	}{
+		{
+			name:      "other domain",
+			errorInfo: &errdetails.ErrorInfo{Reason: "UNAVAILABLE", Domain: "example.com", Metadata: map[string]string{"retriable": "true"}},
+		},
+		{
+			name:      "invalid code",
+			errorInfo: &errdetails.ErrorInfo{Reason: "NOT_A_CODE", Domain: ErrorInfoDomain, Metadata: map[string]string{"code": "invalid"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st, err := status.New(codes.NotFound, "not found").WithDetails(tc.errorInfo)
+			require.NoError(t, err)
+
+			err = makeMockGrpcCall(st.Err())()
+			require.Equal(t, simplerr.CodeNotFound, simplerr.GetCode(err), "the code is mapped from the gRPC code")
+			require.False(t, simplerr.IsRetriable(err))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/lobocv/simplerr"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 // grpcError is a wrapper that exposes a SimpleError in a way that implements the gRPC status interface
@@ -11,6 +12,10 @@ import (
 type grpcError struct {
 	*simplerr.SimpleError
 	code codes.Code
+	// msg is the message sent to clients in the status
+	msg string
+	// details are the details sent to clients in the status
+	details []protoadapt.MessageV1
 }
 
 // Unwrap implement the interface required for error unwrapping
@@ -20,12 +25,18 @@ func (e *grpcError) Unwrap() error {
 
 // GRPCStatus implements an interface that the gRPC framework uses to return the gRPC status code
 func (e *grpcError) GRPCStatus() *status.Status {
-	// If the status was attached as an attribute, return it
-	v, _ := simplerr.GetAttribute(e.SimpleError, AttrGRPCStatus)
+	// If the error is a client error that is returned as is, forward its status. The tree is not searched so that an
+	// error which wraps a client error sends its own code, message and details rather than those of the client error.
+	v, _ := e.SimpleError.GetAttribute(AttrGRPCStatus)
 	st, ok := v.(*status.Status)
 	if ok {
 		return st
 	}
 
-	return status.New(e.code, e.Error())
+	st = status.New(e.code, e.msg)
+	// Details can only be added to statuses with a non-OK code, which is always the case for errors
+	if withDetails, err := st.WithDetails(e.details...); err == nil {
+		st = withDetails
+	}
+	return st
 }
@@ -5,10 +5,15 @@ import (
 	"google.golang.org/grpc/codes"
 )
 
-// Registry is a registry which contains the mapping between simplerr codes and grpc error codes
+// Registry is a registry which contains the mapping between simplerr codes and grpc error codes, as well as the options
+// for the details that are sent in the status of errors
 type Registry struct {
 	toGRPC   map[simplerr.Code]codes.Code
 	fromGRPC map[codes.Code]simplerr.Code
+	// detailAux are the auxiliary keys that are sent in the ErrorInfo detail
+	detailAux []string
+	// debugInfo enables sending the DebugInfo detail
+	debugInfo bool
 }
 
 // NewRegistry creates a new registry which contains the mapping to and from simplerr codes and grpc error codes
@@ -29,6 +34,31 @@ func (r *Registry) SetInverseMapping(m map[codes.Code]simplerr.Code) {
 	r.fromGRPC = m
 }
 
+// SetDetailAux sets the keys of the auxiliary data that are sent to clients in the metadata of the `google.rpc.ErrorInfo`
+// status detail. No auxiliary data is sent by default because it may contain internal details.
+func (r *Registry) SetDetailAux(keys ...string) {
+	r.detailAux = keys
+}
+
+// SetDebugInfo enables sending the full error string and stack trace to clients in a `google.rpc.DebugInfo` status
+// detail. It is disabled by default and should only be enabled for internal services because it leaks internal details.
+func (r *Registry) SetDebugInfo(enabled bool) {
+	r.debugInfo = enabled
+}
+
+// simplerrCodes returns the simplerr codes in the mapping to gRPC codes, except for CodeUnknown
+func (r *Registry) simplerrCodes() []simplerr.Code {
+	var simplerrCodes []simplerr.Code
+	for c := range r.toGRPC {
+		// Ignore CodeUnknown because it is the default code
+		if c == simplerr.CodeUnknown {
+			continue
+		}
+		simplerrCodes = append(simplerrCodes, c)
+	}
+	return simplerrCodes
+}
+
 // getGRPCCode gets the simplerr Code that corresponds to the GRPC code. It returns CodeUnknown if it cannot map the status.
 func (r *Registry) getGRPCCode(grpcCode codes.Code) (code simplerr.Code, found bool) {
 	code, ok := r.fromGRPC[grpcCode]
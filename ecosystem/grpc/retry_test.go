@@ -0,0 +1,162 @@
+package simplegrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lobocv/simplerr"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// testRetryPolicy retries quickly so that the tests are fast
+var testRetryPolicy = simplerr.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.2,
+	RetryCodes:     []simplerr.Code{simplerr.CodeUnavailable},
+}
+
+// retryCall makes a call through the Retry and ReturnSimpleErrors interceptors. The invoker returns the errors in
+// order, one per attempt, and then succeeds. It returns the number of attempts and the error.
+func retryCall(ctx context.Context, policy simplerr.RetryPolicy, errs ...error) (int, error) {
+	var attempts int
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts++
+		if attempts > len(errs) {
+			return nil
+		}
+		return errs[attempts-1]
+	}
+
+	returnSimpleErrors := ReturnSimpleErrors(nil)
+	err := Retry(policy)(ctx, "/ping.PingService/Ping", nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return returnSimpleErrors(ctx, method, req, reply, cc, invoker, opts...)
+	})
+	return attempts, err
+}
+
+func TestRetry(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	t.Run("retry until success", func(t *testing.T) {
+		attempts, err := retryCall(context.Background(), testRetryPolicy, unavailable, unavailable)
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		attempts, err := retryCall(context.Background(), testRetryPolicy, unavailable, unavailable, unavailable, unavailable)
+		require.Equal(t, 3, attempts)
+		require.True(t, simplerr.HasErrorCode(err, simplerr.CodeUnavailable))
+		require.Equal(t, codes.Unavailable, status.Code(err))
+
+		history, ok := simplerr.GetRetryHistory(err)
+		require.True(t, ok)
+		require.Equal(t, 3, history.Attempts)
+		require.Equal(t, []simplerr.Code{simplerr.CodeUnavailable, simplerr.CodeUnavailable, simplerr.CodeUnavailable}, history.Codes)
+
+		method, ok := simplerr.GetAttribute(err, AttrGRPCMethod)
+		require.True(t, ok)
+		require.Equal(t, "/ping.PingService/Ping", method)
+		st, ok := simplerr.GetAttribute(err, AttrGRPCStatus)
+		require.True(t, ok)
+		require.Equal(t, codes.Unavailable, st.(*status.Status).Code()) // nolint: errcheck
+	})
+
+	t.Run("not retried", func(t *testing.T) {
+		attempts, err := retryCall(context.Background(), testRetryPolicy, status.Error(codes.NotFound, "not found"))
+		require.Equal(t, 1, attempts)
+		require.True(t, simplerr.HasErrorCode(err, simplerr.CodeNotFound))
+		history, ok := simplerr.GetRetryHistory(err)
+		require.True(t, ok)
+		require.Equal(t, 1, history.Attempts)
+	})
+
+	t.Run("retriable errors sent by the server", func(t *testing.T) {
+		st, err := status.New(codes.Aborted, "conflict").WithDetails(&errdetails.ErrorInfo{
+			Reason:   "UNKNOWN",
+			Domain:   ErrorInfoDomain,
+			Metadata: map[string]string{"code": "0", "retriable": "true"},
+		})
+		require.NoError(t, err)
+		attempts, err := retryCall(context.Background(), testRetryPolicy, st.Err())
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("retry info delay", func(t *testing.T) {
+		st, err := status.New(codes.ResourceExhausted, "slow down").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(20 * time.Millisecond)})
+		require.NoError(t, err)
+
+		policy := testRetryPolicy
+		policy.InitialBackoff = time.Hour
+		start := time.Now()
+		attempts, err := retryCall(context.Background(), policy, st.Err())
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		require.Less(t, time.Since(start), time.Minute, "the server's delay is used instead of the backoff")
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		policy := testRetryPolicy
+		policy.InitialBackoff = time.Hour
+		attempts, err := retryCall(ctx, policy, unavailable)
+		require.Equal(t, 1, attempts)
+		require.ErrorContains(t, err, "retry aborted: deadline would be exceeded")
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestRetryStream(t *testing.T) {
+	returnSimpleErrorsStream := ReturnSimpleErrorsStream(nil)
+
+	// newStream creates a stream through the RetryStream and ReturnSimpleErrorsStream interceptors. The streamer fails
+	// the given number of times and then succeeds.
+	newStream := func(failures int) (grpc.ClientStream, int, error) {
+		var attempts int
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			attempts++
+			if attempts <= failures {
+				return nil, status.Error(codes.Unavailable, "unavailable")
+			}
+			return serverStreamClient{}, nil
+		}
+		stream, err := RetryStream(testRetryPolicy)(context.Background(), &grpc.StreamDesc{}, nil, "/ping.PingService/Stream", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return returnSimpleErrorsStream(ctx, desc, cc, method, streamer, opts...)
+		})
+		return stream, attempts, err
+	}
+
+	t.Run("retry until success", func(t *testing.T) {
+		stream, attempts, err := newStream(2)
+		require.NoError(t, err)
+		require.NotNil(t, stream)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		stream, attempts, err := newStream(5)
+		require.Nil(t, stream)
+		require.Equal(t, 3, attempts)
+		require.True(t, simplerr.HasErrorCode(err, simplerr.CodeUnavailable))
+		history, ok := simplerr.GetRetryHistory(err)
+		require.True(t, ok)
+		require.Equal(t, 3, history.Attempts)
+	})
+}
+
+// serverStreamClient is a grpc.ClientStream that does nothing
+type serverStreamClient struct {
+	grpc.ClientStream
+}
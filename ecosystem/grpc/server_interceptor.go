@@ -4,26 +4,33 @@ import (
 	"context"
 	"github.com/lobocv/simplerr"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"time"
 )
 
 // TranslateErrorCode inspects the error to see if it is a SimpleError. If it is, it attempts to translate the
-// SimpleError code to the corresponding grpc error code.
+// SimpleError code to the corresponding grpc error code. Errors without a code that are recognized by the simplerr
+// classifiers (eg. `context.Canceled` or `os.ErrNotExist`) are translated by their classified code, even if they are
+// not SimpleErrors.
+// Codes that are not in the registry's mapping fall back to the GRPCCode defined in the simplerr registry's CodeMetadata.
 // If no translation exists it returns a grpc error with Unknown error code.
+// The status sent to the client only contains the client-facing message of the error so that internal details in the
+// error string are not leaked. Localized errors are translated into the languages of the "accept-language" request
+// metadata (see `simplerr.Translate()`), otherwise the public message of the error (see `simplerr.GetPublicMessage()`),
+// or the description of its code if there is none, is sent.
+// The exact simplerr code, flags and retry-after hint of the error are sent as status details so that clients using
+// ReturnSimpleErrors can rebuild the error. See `Registry.SetDetailAux()` and `Registry.SetDebugInfo()` for sending
+// additional details.
 func TranslateErrorCode(registry *Registry) grpc.UnaryServerInterceptor {
 
 	if registry == nil {
 		registry = defaultRegistry
 	}
 
-	// Get a list of simplerr codes to search for in the error chain
-	var simplerrCodes []simplerr.Code
-	for c := range registry.toGRPC {
-		// Ignore CodeUnknown because it is the default code
-		if c == simplerr.CodeUnknown {
-			continue
-		}
-		simplerrCodes = append(simplerrCodes, c)
-	}
+	simplerrCodes := registry.simplerrCodes()
 
 	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		r, err := handler(ctx, req)
@@ -31,24 +38,169 @@ func TranslateErrorCode(registry *Registry) grpc.UnaryServerInterceptor {
 		if err == nil {
 			return r, nil
 		}
+		return r, translateError(ctx, err, registry, simplerrCodes)
+	}
+}
+
+// TranslateErrorCodeStream is the stream server interceptor equivalent of TranslateErrorCode. It translates the error
+// returned by the stream handler in the same way.
+func TranslateErrorCodeStream(registry *Registry) grpc.StreamServerInterceptor {
+
+	if registry == nil {
+		registry = defaultRegistry
+	}
+
+	simplerrCodes := registry.simplerrCodes()
 
-		// Check the error to see if it's a SimpleError, then translate to the gRPC code
-		if e := simplerr.As(err); e != nil {
-
-			// Check if the error has any of the codes in it's chain
-			code, ok := simplerr.HasErrorCodes(e, simplerrCodes...)
-			if !ok {
-				return r, err
-			}
-
-			// Get the gRPC code, this lookup should never fail
-			grpcCode := registry.toGRPC[code]
-			return r, &grpcError{
-				SimpleError: e,
-				code:        grpcCode,
-			}
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		// If no error, return early
+		if err == nil {
+			return nil
 		}
+		return translateError(ss.Context(), err, registry, simplerrCodes)
+	}
+}
+
+// RecoverPanics returns a unary server interceptor that recovers from panics in the handler and returns them as errors
+// with `simplerr.CodeInternal` (see `simplerr.Recover()`). It should be chained after TranslateErrorCode so that
+// the errors are translated like any other error returned by the handler:
+//
+//	grpc.ChainUnaryInterceptor(simplegrpc.TranslateErrorCode(nil), simplegrpc.RecoverPanics())
+func RecoverPanics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer simplerr.Recover(&err)
+		return handler(ctx, req)
+	}
+}
+
+// RecoverPanicsStream is the stream server interceptor equivalent of RecoverPanics. It should be chained after
+// TranslateErrorCodeStream.
+func RecoverPanicsStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer simplerr.Recover(&err)
+		return handler(srv, ss)
+	}
+}
+
+// LogErrors returns a unary server interceptor that logs the errors returned by the handler with `simplerr.Log()`, so
+// that silent errors are skipped and benign errors are logged at INFO level with their benign reason. The logger
+// attached to the error (see `simplerr.SimpleError.GetLogger()`) is used, with the gRPC method, status code, peer
+// address and duration added as log attributes. The error returned by the handler is not modified.
+// It should be chained before TranslateErrorCode so that the translated status code is logged:
+//
+//	grpc.ChainUnaryInterceptor(simplegrpc.LogErrors(), simplegrpc.TranslateErrorCode(nil), simplegrpc.RecoverPanics())
+func LogErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		resp, err = handler(ctx, req)
+		logError(ctx, err, info.FullMethod, start)
+		return resp, err
+	}
+}
+
+// LogErrorsStream is the stream server interceptor equivalent of LogErrors. It should be chained before
+// TranslateErrorCodeStream.
+func LogErrorsStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logError(ss.Context(), err, info.FullMethod, start)
+		return err
+	}
+}
+
+// logError logs the error returned by the handler of the method, if any, with the request details as log attributes
+func logError(ctx context.Context, err error, method string, start time.Time) {
+	if err == nil {
+		return
+	}
+
+	attrs := []any{
+		"grpc_method", method,
+		"grpc_code", status.Code(err).String(),
+		"duration", time.Since(start),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+
+	simplerr.Log(ctx, err, attrs...)
+}
+
+// translateError translates the error returned by a handler to a grpcError with the corresponding gRPC code.
+// simplerrCodes are the codes in the registry's mapping to search for in the error chain.
+func translateError(ctx context.Context, err error, registry *Registry, simplerrCodes []simplerr.Code) error {
+	grpcCode, ok := resolveGRPCCode(err, registry, simplerrCodes)
+
+	// Errors that are not SimpleErrors (eg. a bare `ctx.Err()`) are wrapped so that they can carry the gRPC code
+	e := simplerr.As(err)
+	switch {
+	case e == nil && !ok:
+		return err
+	case e == nil:
+		e = simplerr.Wrap(err)
+	}
+
+	// Only the client-facing message is sent to the client, the full error is kept on the server
+	msg, _ := simplerr.Translate(err, acceptedLanguages(ctx)...)
+	return &grpcError{
+		SimpleError: e,
+		code:        grpcCode,
+		msg:         msg,
+		details:     statusDetails(err, registry),
+	}
+}
+
+// resolveGRPCCode returns the gRPC code for the error. The error's code (see `simplerr.GetCode()`) is resolved with the
+// registry's mapping and then the GRPCCode defined in the simplerr registry's CodeMetadata. If neither has a gRPC code
+// for it, the tree of errors is searched for the first code that does.
+func resolveGRPCCode(err error, registry *Registry, simplerrCodes []simplerr.Code) (codes.Code, bool) {
+	// The error's own code takes precedence over the codes of the errors it wraps
+	if code := simplerr.GetCode(err); code != simplerr.CodeUnknown {
+		if grpcCode, ok := registry.toGRPC[code]; ok {
+			return grpcCode, true
+		}
+		if meta, ok := simplerr.GetRegistry().CodeMetadata(code); ok && meta.GRPCCode != 0 {
+			return codes.Code(meta.GRPCCode), true
+		}
+	}
+
+	// Check if the error has any of the codes in it's tree, this includes any joined errors and classified errors
+	// which have not been given a code
+	code, ok := simplerr.HasErrorCodes(err, simplerrCodes...)
+	if !ok {
+		// Fall back to the gRPC code defined in the simplerr registry
+		return registryGRPCCode(err)
+	}
+	return registry.toGRPC[code], true
+}
+
+// registryGRPCCode returns the gRPC code defined in the CodeMetadata of the simplerr registry for the first code in
+// the error tree that has one.
+func registryGRPCCode(err error) (codes.Code, bool) {
+	metadata := simplerr.GetRegistry().ErrorCodeMetadata()
+	var simplerrCodes []simplerr.Code
+	for c, meta := range metadata {
+		if meta.GRPCCode != 0 {
+			simplerrCodes = append(simplerrCodes, c)
+		}
+	}
+
+	code, ok := simplerr.HasErrorCodes(err, simplerrCodes...)
+	if !ok {
+		return codes.Unknown, false
+	}
+	return codes.Code(metadata[code].GRPCCode), true
+}
 
-		return r, err
+// acceptedLanguages returns the languages of the "accept-language" metadata of the incoming request in order of
+// preference. The metadata uses the same format as the HTTP Accept-Language header.
+func acceptedLanguages(ctx context.Context) []string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var langs []string
+	for _, v := range md.Get("accept-language") {
+		langs = append(langs, simplerr.AcceptedLanguages(v)...)
 	}
+	return langs
 }
@@ -1,12 +1,22 @@
 package simplegrpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/lobocv/simplerr"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"io/fs"
+	"log/slog"
+	"net"
 	"testing"
 )
 
@@ -23,6 +33,12 @@ func TestTranslateErrorCode(t *testing.T) {
 		{fmt.Errorf("wrapped: %w", simplerr.New("something").Code(simplerr.CodeUnauthenticated)), codes.Unauthenticated},
 		{fmt.Errorf("opaque: %s", simplerr.New("something").Code(simplerr.CodeUnauthenticated)), codes.Unknown},
 		{simplerr.Wrap(simplerr.New("something").Code(simplerr.CodePermissionDenied)), codes.PermissionDenied},
+		{errors.Join(simplerr.New("something"), simplerr.New("something").Code(simplerr.CodeNotFound)), codes.NotFound},
+		{simplerr.Join(fmt.Errorf("something"), simplerr.New("something").Code(simplerr.CodeNotFound)), codes.NotFound},
+		{context.Canceled, codes.Canceled},
+		{fmt.Errorf("handler: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{simplerr.Wrap(context.DeadlineExceeded).Code(simplerr.CodeNotFound), codes.NotFound},
+		{fmt.Errorf("open config: %w", fs.ErrPermission), codes.PermissionDenied},
 		{nil, codes.OK},
 	}
 
@@ -42,7 +58,8 @@ func TestTranslateErrorCode(t *testing.T) {
 		require.Equal(t, tc.expected, grpcStatusCode)
 
 		// Check that the translated error can still be detected as a SimpleError
-		expectSimplerr := simplerr.As(tc.err) != nil
+		_, classified := simplerr.Classify(tc.err)
+		expectSimplerr := simplerr.As(tc.err) != nil || classified
 		if expectSimplerr {
 			gotSimplerr := simplerr.As(gotErr) != nil
 			require.True(t, gotSimplerr)
@@ -51,6 +68,131 @@ func TestTranslateErrorCode(t *testing.T) {
 
 }
 
+func TestTranslateErrorCodeRegistryFallback(t *testing.T) {
+	const CodeCustom = 100
+	r := simplerr.NewRegistry()
+	r.RegisterCode(CodeCustom, simplerr.CodeMetadata{Description: "custom", GRPCCode: uint32(codes.Aborted)})
+	r.RegisterCode(CodeCustom+1, simplerr.CodeMetadata{Description: "no grpc code"})
+	defaultRegistry := simplerr.GetRegistry()
+	simplerr.SetRegistry(r)
+	defer simplerr.SetRegistry(defaultRegistry)
+
+	testCases := []struct {
+		err      error
+		expected codes.Code
+	}{
+		{simplerr.New("something").Code(CodeCustom), codes.Aborted},
+		{simplerr.Wrap(simplerr.New("something").Code(CodeCustom)).Code(CodeCustom + 1), codes.Aborted},
+		{simplerr.New("something").Code(CodeCustom + 1), codes.Unknown},
+		{simplerr.New("something").Code(simplerr.CodeNotFound), codes.NotFound},
+		// The error's own code takes precedence over the codes of the errors it wraps
+		{simplerr.Wrap(simplerr.New("something").Code(simplerr.CodeNotFound)).Code(CodeCustom), codes.Aborted},
+		{simplerr.Wrap(simplerr.New("something").Code(CodeCustom)).Code(simplerr.CodeNotFound), codes.NotFound},
+		{simplerr.Wrap(simplerr.New("something").Code(simplerr.CodeNotFound)).Code(CodeCustom + 1), codes.NotFound},
+	}
+
+	interceptor := TranslateErrorCode(nil)
+	for _, tc := range testCases {
+		_, gotErr := interceptor(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return 1, tc.err
+		})
+		require.Equal(t, tc.expected, status.Code(gotErr))
+		require.NotNil(t, simplerr.As(gotErr))
+	}
+}
+
+func TestTranslateErrorCodePublicMessage(t *testing.T) {
+	testCases := []struct {
+		err         error
+		expectedMsg string
+		expected    codes.Code
+	}{
+		{simplerr.Wrapf(simplerr.New("SELECT * FROM users").Code(simplerr.CodeNotFound).Public("user not found"), "db.example.internal"), "user not found", codes.NotFound},
+		{simplerr.New("dial tcp 10.0.0.1:5432").Code(simplerr.CodeUnavailable), "unavailable", codes.Unavailable},
+		{simplerr.New("unmapped secret").Code(simplerr.CodeMissingParameter), "parameter is missing", codes.Unknown},
+		{fmt.Errorf("query: %w", context.Canceled), "canceled", codes.Canceled},
+	}
+
+	interceptor := TranslateErrorCode(nil)
+	for _, tc := range testCases {
+		_, gotErr := interceptor(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return 1, tc.err
+		})
+		st, ok := status.FromError(gotErr)
+		require.True(t, ok)
+		require.Equal(t, tc.expected, st.Code())
+		require.Equal(t, tc.expectedMsg, st.Message())
+		require.Equal(t, tc.err.Error(), gotErr.Error(), "the full error is kept on the server")
+	}
+}
+
+func TestTranslateErrorCodeLocalized(t *testing.T) {
+	catalog := simplerr.NewMessageCatalog("en")
+	catalog.Add("en", map[string]string{"order.not_found": "Order {id} was not found"})
+	catalog.Add("fr", map[string]string{"order.not_found": "La commande {id} est introuvable"})
+
+	r := simplerr.NewRegistry()
+	r.SetCatalog(catalog)
+	defaultRegistry := simplerr.GetRegistry()
+	simplerr.SetRegistry(r)
+	defer simplerr.SetRegistry(defaultRegistry)
+
+	err := simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).Localized("order.not_found", "id", 42)
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		expectedMsg string
+	}{
+		{
+			name:        "preferred language",
+			ctx:         metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "de", "accept-language", "fr-CA;q=0.9")),
+			expectedMsg: "La commande 42 est introuvable",
+		},
+		{
+			name:        "no metadata",
+			ctx:         context.Background(),
+			expectedMsg: "Order 42 was not found",
+		},
+	}
+
+	interceptor := TranslateErrorCode(nil)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, gotErr := interceptor(tc.ctx, nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, err
+			})
+			st, ok := status.FromError(gotErr)
+			require.True(t, ok)
+			require.Equal(t, codes.NotFound, st.Code())
+			require.Equal(t, tc.expectedMsg, st.Message())
+		})
+	}
+}
+
+func TestTranslateErrorCodeFieldViolations(t *testing.T) {
+	interceptor := TranslateErrorCode(nil)
+	_, gotErr := interceptor(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, simplerr.Wrapf(simplerr.Invalid().Field("email", "must be a valid address"), "create user").Field("age", "must be at least 18")
+	})
+
+	st, ok := status.FromError(gotErr)
+	require.True(t, ok)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 2)
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.GetFieldViolations(), 2)
+	require.Equal(t, "age", badRequest.GetFieldViolations()[0].GetField())
+	require.Equal(t, "must be at least 18", badRequest.GetFieldViolations()[0].GetDescription())
+	require.Equal(t, "email", badRequest.GetFieldViolations()[1].GetField())
+	require.Equal(t, "must be a valid address", badRequest.GetFieldViolations()[1].GetDescription())
+
+	// Details cannot be added to an OK status
+	okErr := &grpcError{SimpleError: simplerr.New("ok"), code: codes.OK, details: statusDetails(simplerr.New("ok"), NewRegistry())}
+	require.Empty(t, okErr.GRPCStatus().Details())
+}
+
 // Test that multiple different registry can be used at the same time
 func TestMultipleRegistry(t *testing.T) {
 	ctx := context.Background()
@@ -74,3 +216,113 @@ func TestMultipleRegistry(t *testing.T) {
 	})
 	require.Equal(t, codes.Internal, status.Code(gotErr))
 }
+
+func TestRecoverPanics(t *testing.T) {
+	translate := TranslateErrorCode(nil)
+	recoverPanics := RecoverPanics()
+
+	_, err := translate(context.Background(), nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return recoverPanics(ctx, req, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+	})
+	require.EqualError(t, err, "panic: boom")
+	require.ErrorIs(t, err, simplerr.ErrPanic)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.Internal, st.Code())
+	require.Equal(t, "internal error", st.Message(), "the panic is not leaked to the client")
+}
+
+// serverStream is a grpc.ServerStream with a context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestLogErrors(t *testing.T) {
+	var output bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&output, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}})
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected map[string]interface{}
+	}{
+		{
+			name: "error",
+			ctx:  peerCtx,
+			err:  simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).Aux("order_id", 42),
+			expected: map[string]interface{}{
+				"level": "ERROR", "msg": "order 42 not found", "order_id": float64(42),
+				"grpc_method": "/simplerr.test.Orders/Get", "grpc_code": "NotFound", "peer": "192.0.2.1:1234",
+			},
+		},
+		{
+			name: "benign error without peer",
+			ctx:  context.Background(),
+			err:  simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).BenignReason("user typo"),
+			expected: map[string]interface{}{
+				"level": "INFO", "msg": "order 42 not found", "benign_reason": "user typo",
+				"grpc_method": "/simplerr.test.Orders/Get", "grpc_code": "NotFound",
+			},
+		},
+		{
+			name: "silent error",
+			ctx:  peerCtx,
+			err:  simplerr.New("order 42 not found").Silence(),
+		},
+		{
+			name: "no error",
+			ctx:  peerCtx,
+		},
+	}
+
+	// The errors are translated by the inner interceptor so that the gRPC code is logged
+	translate := TranslateErrorCode(nil)
+	translateStream := TranslateErrorCodeStream(nil)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := func() {
+				if tc.expected == nil {
+					require.Empty(t, output.String())
+					return
+				}
+				got := map[string]interface{}{}
+				require.NoError(t, json.Unmarshal(output.Bytes(), &got))
+				require.NotZero(t, got["duration"])
+				delete(got, "duration")
+				delete(got, "time")
+				require.Equal(t, tc.expected, got)
+			}
+
+			output.Reset()
+			_, err := LogErrors()(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/simplerr.test.Orders/Get"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return translate(ctx, req, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return nil, tc.err
+				})
+			})
+			require.Equal(t, tc.err == nil, err == nil, "the error is returned")
+			check()
+
+			output.Reset()
+			err = LogErrorsStream()(nil, serverStream{ctx: tc.ctx}, &grpc.StreamServerInfo{FullMethod: "/simplerr.test.Orders/Get"}, func(srv interface{}, ss grpc.ServerStream) error {
+				return translateStream(srv, ss, nil, func(srv interface{}, ss grpc.ServerStream) error {
+					return tc.err
+				})
+			})
+			require.Equal(t, tc.err == nil, err == nil, "the error is returned")
+			check()
+		})
+	}
+}
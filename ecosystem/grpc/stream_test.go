@@ -0,0 +1,165 @@
+package simplegrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/lobocv/simplerr"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echoServiceDesc describes a service with streaming methods which echo the received messages back to the client.
+// Receiving the message "fail" makes the handler return the error given to newStreamClient.
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "simplerr.test.Echo",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{StreamName: "ServerStream", Handler: echoHandler, ServerStreams: true},
+		{StreamName: "BidiStream", Handler: echoHandler, ServerStreams: true, ClientStreams: true},
+	},
+}
+
+// echoHandler echoes messages until the client closes the stream or sends "fail"
+func echoHandler(srv interface{}, stream grpc.ServerStream) error {
+	for {
+		msg := &wrapperspb.StringValue{}
+		if err := stream.RecvMsg(msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.GetValue() == "fail" {
+			return srv.(func() error)() // nolint: errcheck
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// newStreamClient starts a server with the stream interceptors which serves the echo service and returns a client
+// connection that uses the stream client interceptor. The handler returns the error returned by fail.
+func newStreamClient(t *testing.T, fail func() error) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ChainStreamInterceptor(TranslateErrorCodeStream(nil), RecoverPanicsStream()))
+	server.RegisterService(&echoServiceDesc, fail)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(ReturnSimpleErrorsStream(nil)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	conn := newStreamClient(t, func() error {
+		return simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).Public("order not found").Field("id", "does not exist")
+	})
+
+	for _, desc := range echoServiceDesc.Streams {
+		t.Run(desc.StreamName, func(t *testing.T) {
+			method := "/simplerr.test.Echo/" + desc.StreamName
+			stream, err := conn.NewStream(context.Background(), &desc, method)
+			require.NoError(t, err)
+
+			reply := &wrapperspb.StringValue{}
+			// Server streaming clients can only send a single message
+			if desc.ClientStreams {
+				require.NoError(t, stream.SendMsg(wrapperspb.String("hello")))
+				require.NoError(t, stream.RecvMsg(reply))
+				require.Equal(t, "hello", reply.GetValue())
+			}
+
+			require.NoError(t, stream.SendMsg(wrapperspb.String("fail")))
+			err = stream.RecvMsg(reply)
+			require.Equal(t, "order not found", err.Error())
+			require.True(t, simplerr.HasErrorCode(err, simplerr.CodeNotFound), "the error is translated on the server and converted on the client")
+			require.Equal(t, codes.NotFound, status.Code(err))
+			require.Equal(t, []simplerr.FieldViolation{{Field: "id", Description: "does not exist"}}, simplerr.GetFieldViolations(err))
+			require.Contains(t, simplerr.As(err).StackTrace()[0].FuncName, "TestStreamInterceptors",
+				"the stack trace starts at the caller of RecvMsg")
+
+			gotMethod, ok := simplerr.GetAttribute(err, AttrGRPCMethod)
+			require.True(t, ok)
+			require.Equal(t, method, gotMethod)
+		})
+	}
+}
+
+func TestStreamInterceptorsEndOfStream(t *testing.T) {
+	conn := newStreamClient(t, func() error { return nil })
+
+	desc := echoServiceDesc.Streams[1]
+	stream, err := conn.NewStream(context.Background(), &desc, "/simplerr.test.Echo/BidiStream")
+	require.NoError(t, err)
+
+	require.NoError(t, stream.SendMsg(wrapperspb.String("fail")))
+	require.NoError(t, stream.CloseSend())
+	require.Equal(t, io.EOF, stream.RecvMsg(&wrapperspb.StringValue{}), "the end of the stream is not converted")
+}
+
+func TestStreamInterceptorsSendError(t *testing.T) {
+	conn := newStreamClient(t, func() error { return nil })
+
+	desc := echoServiceDesc.Streams[1]
+	stream, err := conn.NewStream(context.Background(), &desc, "/simplerr.test.Echo/BidiStream")
+	require.NoError(t, err)
+
+	// Messages which are not protos cannot be marshalled
+	err = stream.SendMsg("not a proto")
+	require.NotNil(t, simplerr.As(err))
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestStreamServerInterceptorNotTranslated(t *testing.T) {
+	conn := newStreamClient(t, func() error { return errors.New("no code") })
+
+	desc := echoServiceDesc.Streams[1]
+	stream, err := conn.NewStream(context.Background(), &desc, "/simplerr.test.Echo/BidiStream")
+	require.NoError(t, err)
+
+	require.NoError(t, stream.SendMsg(wrapperspb.String("fail")))
+	err = stream.RecvMsg(&wrapperspb.StringValue{})
+	require.Equal(t, codes.Unknown, status.Code(err))
+	require.Equal(t, "no code", err.Error())
+}
+
+func TestStreamClientInterceptorStreamError(t *testing.T) {
+	interceptor := ReturnSimpleErrorsStream(nil)
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+
+	stream, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/simplerr.test.Echo/BidiStream", streamer)
+	require.Nil(t, stream)
+	require.True(t, simplerr.HasErrorCode(err, simplerr.CodeUnavailable))
+	require.Equal(t, "connection refused", err.Error())
+}
+
+func TestStreamRecoverPanics(t *testing.T) {
+	conn := newStreamClient(t, func() error { panic("boom") })
+
+	desc := echoServiceDesc.Streams[1]
+	stream, err := conn.NewStream(context.Background(), &desc, "/simplerr.test.Echo/BidiStream")
+	require.NoError(t, err)
+
+	require.NoError(t, stream.SendMsg(wrapperspb.String("fail")))
+	err = stream.RecvMsg(&wrapperspb.StringValue{})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.True(t, simplerr.HasErrorCode(err, simplerr.CodeInternal))
+	require.Equal(t, "internal error", err.Error())
+}
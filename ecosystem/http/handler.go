@@ -1,7 +1,10 @@
 package simplehttp
 
 import (
+	"encoding/json"
 	"net/http"
+
+	"github.com/lobocv/simplerr"
 )
 
 // ErrorHandler is an error handling function for HTTP handlers
@@ -14,6 +17,48 @@ var DefaultErrorHandler ErrorHandler = func(w http.ResponseWriter, r *http.Reque
 	SetStatus(w, err)
 }
 
+// JSONErrorHandler is an error handling function for HTTP handlers which sets the response status like the
+// DefaultErrorHandler and also writes the error to the response body with WriteError.
+// It can be used with the WithErrorHandler option or by assigning it to the DefaultErrorHandler.
+var JSONErrorHandler ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+	WriteError(w, r, err)
+}
+
+// ErrorResponse is the JSON body written by WriteError
+type ErrorResponse struct {
+	// Message is the public message of the error
+	Message string `json:"message"`
+	// Violations are the field violations of the error, if any (see `simplerr.GetFieldViolations()`)
+	Violations []simplerr.FieldViolation `json:"violations,omitempty"`
+}
+
+// WriteError sets the response status from the error like SetStatus and writes an ErrorResponse to the body.
+// Only the client-facing message of the error is written so that internal details in the error string are not leaked
+// to clients. Localized errors are translated into the languages of the request's Accept-Language header
+// (see `simplerr.Translate()`), otherwise the public message of the error (see `simplerr.GetPublicMessage()`), or the
+// description of its code if there is none, is written. The request may be nil.
+// The field violations of the error are also written. Errors created with `simplerr.Invalid()` map to status 422
+// (Unprocessable Entity) by default.
+// If the error is nil, or the response has already been written by the handler or by an error handler of another
+// middleware layer (see ApplyMiddleware), nothing is written.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	if _, written := writtenStatus(w); written {
+		return
+	}
+	var langs []string
+	if r != nil {
+		langs = simplerr.AcceptedLanguages(r.Header.Get("Accept-Language"))
+	}
+	msg, _ := simplerr.Translate(err, langs...)
+
+	w.Header().Set("Content-Type", "application/json")
+	SetStatus(w, err)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{Message: msg, Violations: simplerr.GetFieldViolations(err)})
+}
+
 // Handler is analogous to http.Handler but returns an error
 type Handler interface {
 	ServeHTTP(http.ResponseWriter, *http.Request) error
@@ -36,6 +81,7 @@ func NewHandlerAdapter(h Handler, opts ...HandlerOption) *HandlerAdapter {
 
 // ServeHTTP calls the underlying handler's ServeHTTP method and calls SetStatus on the returned error
 func (h HandlerAdapter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writer = newResponseWriter(writer)
 	err := h.h.ServeHTTP(writer, request)
 	h.errHandler(writer, request, err)
 }
@@ -1,6 +1,7 @@
 package simplehttp
 
 import (
+	"fmt"
 	"github.com/lobocv/simplerr"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -97,3 +98,92 @@ func TestErrorHandlerChangeSpecific(t *testing.T) {
 	require.True(t, customErrHandlerCalled, "custom err handler should be called")
 	require.False(t, defaultErrHandlerCalled, "default err handler should not be called")
 }
+
+func TestWriteError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "public message",
+			err:            simplerr.Wrapf(simplerr.New("SELECT * FROM users: no rows").Code(simplerr.CodeNotFound).Public("user not found"), "db.example.internal"),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"user not found"}`,
+		},
+		{
+			name:           "code description fallback",
+			err:            simplerr.New("dial tcp 10.0.0.1:5432: connection refused").Code(simplerr.CodeUnavailable),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"message":"unavailable"}`,
+		},
+		{
+			name:           "field violations",
+			err:            simplerr.Wrapf(simplerr.Invalid().Field("email", "must be a valid address"), "create user").Field("age", "must be at least 18"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"message":"invalid argument","violations":[{"field":"age","description":"must be at least 18"},{"field":"email","description":"must be a valid address"}]}`,
+		},
+		{
+			name:           "not a SimpleError",
+			err:            fmt.Errorf("secret"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"unknown"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ep := &Endpoint{err: tc.err}
+			rec := httptest.NewRecorder()
+			NewHandlerAdapter(ep, WithErrorHandler(JSONErrorHandler)).ServeHTTP(rec, nil)
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			require.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+
+	t.Run("nil error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteError(rec, nil, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Body.String())
+	})
+}
+
+func TestWriteErrorLocalized(t *testing.T) {
+	catalog := simplerr.NewMessageCatalog("en")
+	catalog.Add("en", map[string]string{"order.not_found": "Order {id} was not found"})
+	catalog.Add("fr", map[string]string{"order.not_found": "La commande {id} est introuvable"})
+
+	r := simplerr.NewRegistry()
+	r.SetCatalog(catalog)
+	defaultRegistry := simplerr.GetRegistry()
+	simplerr.SetRegistry(r)
+	defer simplerr.SetRegistry(defaultRegistry)
+
+	err := simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).Localized("order.not_found", "id", 42)
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expectedBody   string
+	}{
+		{name: "preferred language", acceptLanguage: "de;q=0.5, fr-CA, en;q=0.8", expectedBody: `{"message":"La commande 42 est introuvable"}`},
+		{name: "default language", acceptLanguage: "de", expectedBody: `{"message":"Order 42 was not found"}`},
+		{name: "no header", expectedBody: `{"message":"Order 42 was not found"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			WriteError(rec, req, err)
+			require.Equal(t, http.StatusNotFound, rec.Code)
+			require.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
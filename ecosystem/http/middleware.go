@@ -2,6 +2,9 @@ package simplehttp
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/lobocv/simplerr"
 )
 
 // Middleware is an HTTP middleware
@@ -11,6 +14,9 @@ type Middleware = func(Handler) Handler
 func middleware(m Middleware) Middleware {
 	return func(h Handler) Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) error {
+			// The error handler is called by each layer, so track whether the response has been written to avoid
+			// writing the error more than once
+			w = newResponseWriter(w)
 			err := m(h).ServeHTTP(w, r)
 			if err != nil {
 				DefaultErrorHandler(w, r, err)
@@ -30,6 +36,53 @@ func ApplyMiddleware(h HandlerFunc, mw ...Middleware) HandlerFunc {
 	return h
 }
 
+// Recoverer is a Middleware that recovers from panics in the handler and returns them as errors with
+// `simplerr.CodeInternal` (see `simplerr.Recover()`), so that they are handled like any other error returned by the
+// handler. It can be used with ApplyMiddleware or, for standard library handlers, with MiddlewareReverseAdapter.
+func Recoverer(h Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+		defer simplerr.Recover(&err)
+		return h.ServeHTTP(w, r)
+	})
+}
+
+// ErrorLogger is a Middleware that logs the errors returned by the handler with `simplerr.Log()`, so that silent errors
+// are skipped and benign errors are logged at INFO level with their benign reason. The logger attached to the error
+// (see `simplerr.SimpleError.GetLogger()`) is used, with the request method, route, response status, peer address and
+// duration added as log attributes. The error returned by the handler is not modified.
+// The route is the pattern matched by the http.ServeMux, or the request path if there is none. The response status is
+// the status already written to the response, if any, otherwise the status that the error translates to.
+func ErrorLogger(h Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		start := time.Now()
+		err := h.ServeHTTP(w, r)
+		if err == nil {
+			return nil
+		}
+
+		// Inner middleware layers may have already written the error to the response
+		status, ok := writtenStatus(w)
+		if !ok {
+			if status, ok = GetStatus(err); !ok {
+				status = defaultErrorStatus
+			}
+		}
+		route := r.Pattern
+		if route == "" {
+			route = r.URL.Path
+		}
+
+		simplerr.Log(r.Context(), err,
+			"http_method", r.Method,
+			"http_route", route,
+			"http_status", status,
+			"peer", r.RemoteAddr,
+			"duration", time.Since(start),
+		)
+		return err
+	})
+}
+
 // middlewareAdapter adapts the http.Handler into a Handler
 type middlewareAdapter struct {
 	h http.HandlerFunc
@@ -69,6 +122,7 @@ type middlewareReverseAdapter struct {
 // ServeHTTP satisfies the Handler interface, calls the error handler but disregards returning any errors because
 // it needs to satisfy the http.Handler interface
 func (a middlewareReverseAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w = newResponseWriter(w)
 	err := a.h.ServeHTTP(w, r)
 	if err != nil {
 		DefaultErrorHandler(w, r, err)
@@ -1,8 +1,11 @@
 package simplehttp
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -306,3 +309,183 @@ func TestMiddlewareReverseAdapter(t *testing.T) {
 	})
 
 }
+
+func TestRecoverer(t *testing.T) {
+
+	ep := func(writer http.ResponseWriter, request *http.Request) error {
+		panic("boom")
+	}
+
+	req, err := http.NewRequest("GET", "url", nil)
+	require.NoError(t, err)
+
+	t.Run("with apply middleware", func(t *testing.T) {
+		ep := ApplyMiddleware(ep, Recoverer)
+		rec := httptest.NewRecorder()
+		err := ep(rec, req)
+		require.EqualError(t, err, "panic: boom")
+		require.ErrorIs(t, err, simplerr.ErrPanic)
+		require.True(t, simplerr.HasErrorCode(err, simplerr.CodeInternal))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("with reverse adapter", func(t *testing.T) {
+		var gotErr error
+		defaultErrorHandler := DefaultErrorHandler
+		DefaultErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			gotErr = err
+			JSONErrorHandler(w, r, err)
+		}
+		defer func() { DefaultErrorHandler = defaultErrorHandler }()
+
+		h := MiddlewareReverseAdapter(Recoverer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.ErrorIs(t, gotErr, simplerr.ErrPanic)
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.JSONEq(t, `{"message":"internal error"}`, rec.Body.String(), "the panic is not leaked to the client")
+	})
+
+	t.Run("aborted handlers are not recovered", func(t *testing.T) {
+		ep := ApplyMiddleware(func(writer http.ResponseWriter, request *http.Request) error {
+			panic(http.ErrAbortHandler)
+		}, Recoverer)
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			_ = ep(httptest.NewRecorder(), req)
+		})
+	})
+}
+
+func TestErrorLogger(t *testing.T) {
+	var output bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&output, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected map[string]interface{}
+	}{
+		{
+			name: "error",
+			err:  simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).Aux("order_id", 42),
+			expected: map[string]interface{}{
+				"level": "ERROR", "msg": "order 42 not found", "order_id": float64(42),
+				"http_method": "GET", "http_route": "GET /orders/{id}", "http_status": float64(http.StatusNotFound), "peer": "192.0.2.1:1234",
+			},
+		},
+		{
+			name: "benign error",
+			err:  simplerr.New("order 42 not found").Code(simplerr.CodeNotFound).BenignReason("user typo"),
+			expected: map[string]interface{}{
+				"level": "INFO", "msg": "order 42 not found", "benign_reason": "user typo",
+				"http_method": "GET", "http_route": "GET /orders/{id}", "http_status": float64(http.StatusNotFound), "peer": "192.0.2.1:1234",
+			},
+		},
+		{
+			name: "not a SimpleError",
+			err:  fmt.Errorf("something"),
+			expected: map[string]interface{}{
+				"level": "ERROR", "msg": "something",
+				"http_method": "GET", "http_route": "GET /orders/{id}", "http_status": float64(http.StatusInternalServerError), "peer": "192.0.2.1:1234",
+			},
+		},
+		{
+			name: "silent error",
+			err:  simplerr.New("order 42 not found").Silence(),
+		},
+		{
+			name: "no error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output.Reset()
+			mux := http.NewServeMux()
+			mux.Handle("GET /orders/{id}", ApplyMiddleware(func(w http.ResponseWriter, r *http.Request) error {
+				return tc.err
+			}, ErrorLogger).Adapter())
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			mux.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tc.expected == nil {
+				require.Empty(t, output.String())
+				return
+			}
+			got := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(output.Bytes(), &got))
+			require.NotZero(t, got["duration"])
+			delete(got, "duration")
+			delete(got, "time")
+			require.Equal(t, tc.expected, got)
+		})
+	}
+
+	t.Run("route falls back to the path", func(t *testing.T) {
+		output.Reset()
+		ep := ApplyMiddleware(func(w http.ResponseWriter, r *http.Request) error {
+			return simplerr.New("something")
+		}, ErrorLogger)
+		_ = ep(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+		require.Contains(t, output.String(), `"http_route":"/orders"`)
+		require.Contains(t, output.String(), `"http_method":"POST"`)
+	})
+
+	t.Run("status already written", func(t *testing.T) {
+		output.Reset()
+		ep := ApplyMiddleware(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusConflict)
+			return simplerr.New("order 42 not found").Code(simplerr.CodeNotFound)
+		}, ErrorLogger)
+		_ = ep(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+		require.Contains(t, output.String(), fmt.Sprintf(`"http_status":%d`, http.StatusConflict))
+	})
+}
+
+func TestErrorWrittenOnceByMiddleware(t *testing.T) {
+	defaultErrorHandler := DefaultErrorHandler
+	DefaultErrorHandler = JSONErrorHandler
+	defer func() { DefaultErrorHandler = defaultErrorHandler }()
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	ep := ApplyMiddleware(func(w http.ResponseWriter, r *http.Request) error {
+		return simplerr.New("order 42 not found").Code(simplerr.CodeNotFound)
+	}, Recoverer, ErrorLogger)
+
+	t.Run("with http adapter", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ep.Adapter()(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "{\"message\":\"not found\"}\n", rec.Body.String())
+	})
+
+	t.Run("without http adapter", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		err := ep(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+		require.True(t, simplerr.HasErrorCode(err, simplerr.CodeNotFound))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "{\"message\":\"not found\"}\n", rec.Body.String())
+	})
+
+	t.Run("with reverse adapter", func(t *testing.T) {
+		h := MiddlewareReverseAdapter(ErrorCausingMiddleware(true))(http.NotFoundHandler())
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Equal(t, "{\"message\":\"invalid argument\"}\n", rec.Body.String())
+	})
+
+	t.Run("status of a written response is not changed", func(t *testing.T) {
+		w := newResponseWriter(httptest.NewRecorder())
+		w.WriteHeader(http.StatusAccepted)
+		require.Equal(t, http.StatusAccepted, SetStatus(w, simplerr.New("something").Code(simplerr.CodeNotFound)))
+	})
+}
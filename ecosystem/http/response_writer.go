@@ -0,0 +1,77 @@
+package simplehttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseWriter is a http.ResponseWriter that records the status of the response once it has been written, so that
+// errors handled by several middleware layers are only written to the response once
+type responseWriter struct {
+	http.ResponseWriter
+	status  HTTPStatus
+	written bool
+}
+
+// newResponseWriter wraps the http.ResponseWriter in a responseWriter, unless it already is one
+func newResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	if _, ok := w.(*responseWriter); ok {
+		return w
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+// writtenStatus returns the status of the response and true if the response has already been written. Only responses
+// written through a responseWriter are detected.
+func writtenStatus(w http.ResponseWriter) (HTTPStatus, bool) {
+	rw, ok := w.(*responseWriter)
+	if !ok || !rw.written {
+		return 0, false
+	}
+	return rw.status, true
+}
+
+// WriteHeader records the status and writes it to the underlying http.ResponseWriter
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.written {
+		w.status = status
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes to the underlying http.ResponseWriter, which implicitly writes the status 200 if it has not been written
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.status = http.StatusOK
+		w.written = true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying http.ResponseWriter does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.written {
+			w.status = http.StatusOK
+			w.written = true
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying http.ResponseWriter does
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	w.written = true
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter so that it can be used with http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
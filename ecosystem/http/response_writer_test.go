@@ -0,0 +1,93 @@
+package simplehttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// plainResponseWriter is a http.ResponseWriter which is neither a http.Flusher nor a http.Hijacker
+type plainResponseWriter struct {
+	http.ResponseWriter
+}
+
+// hijackableResponseWriter is a http.ResponseWriter which is a http.Hijacker
+type hijackableResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w hijackableResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestResponseWriter(t *testing.T) {
+	t.Run("wrapped once", func(t *testing.T) {
+		w := newResponseWriter(httptest.NewRecorder())
+		require.Same(t, w, newResponseWriter(w))
+	})
+
+	t.Run("write header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := newResponseWriter(rec)
+		_, written := writtenStatus(w)
+		require.False(t, written)
+
+		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(http.StatusInternalServerError)
+		status, written := writtenStatus(w)
+		require.True(t, written)
+		require.Equal(t, http.StatusNotFound, status, "only the first status is written")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := newResponseWriter(rec)
+		_, err := w.Write([]byte("done"))
+		require.NoError(t, err)
+		status, written := writtenStatus(w)
+		require.True(t, written)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "done", rec.Body.String())
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := newResponseWriter(rec)
+		w.(http.Flusher).Flush() // nolint: errcheck
+		require.True(t, rec.Flushed)
+		status, written := writtenStatus(w)
+		require.True(t, written)
+		require.Equal(t, http.StatusOK, status)
+
+		w = newResponseWriter(plainResponseWriter{httptest.NewRecorder()})
+		w.(http.Flusher).Flush() // nolint: errcheck
+		_, written = writtenStatus(w)
+		require.False(t, written, "flushing is a no-op if the underlying writer cannot flush")
+	})
+
+	t.Run("hijack", func(t *testing.T) {
+		w := newResponseWriter(plainResponseWriter{httptest.NewRecorder()})
+		_, _, err := w.(http.Hijacker).Hijack() // nolint: errcheck
+		require.ErrorIs(t, err, http.ErrNotSupported)
+		_, written := writtenStatus(w)
+		require.False(t, written)
+
+		w = newResponseWriter(hijackableResponseWriter{httptest.NewRecorder()})
+		_, _, err = w.(http.Hijacker).Hijack() // nolint: errcheck
+		require.NoError(t, err)
+		_, written = writtenStatus(w)
+		require.True(t, written)
+	})
+
+	t.Run("unwrap", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := newResponseWriter(rec)
+		require.NoError(t, http.NewResponseController(w).Flush())
+		require.Same(t, rec, w.(interface{ Unwrap() http.ResponseWriter }).Unwrap()) // nolint: errcheck
+	})
+}
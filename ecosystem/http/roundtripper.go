@@ -1,8 +1,13 @@
 package simplehttp
 
 import (
-	"github.com/lobocv/simplerr"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
+
+	"github.com/lobocv/simplerr"
 )
 
 type attr int
@@ -28,14 +33,38 @@ func (s roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
 		serr := simplerr.New("%s", resp.Status).
 			Code(code).
 			Attr(attrHTTPResponse, resp)
+		for _, v := range readViolations(resp) {
+			_ = serr.Field(v.Field, v.Description)
+		}
 		return nil, serr
 	}
 
 	return resp, nil
 }
 
+// readViolations reads the field violations from a JSON ErrorResponse body, as written by WriteError. The body is
+// replaced so that it can still be read from the response attached to the error.
+func readViolations(resp *http.Response) []simplerr.FieldViolation {
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "application/json" || resp.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var errResp ErrorResponse
+	if err = json.Unmarshal(body, &errResp); err != nil {
+		return nil
+	}
+	return errResp.Violations
+}
+
 // EnableHTTPStatusErrors wraps the http.RoundTripper in middleware that converts 4XX and 5XX series errors to SimpleErrors
-// with the code defined in the inverse mapping.
+// with the code defined in the inverse mapping. The field violations of JSON error responses written by WriteError are
+// added to the error (see `simplerr.GetFieldViolations()`).
 func EnableHTTPStatusErrors(rt http.RoundTripper) http.RoundTripper {
 	return roundTripper{rt: rt}
 }
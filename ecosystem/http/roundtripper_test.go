@@ -1,10 +1,14 @@
 package simplehttp
 
 import (
+	"errors"
 	"fmt"
 	"github.com/lobocv/simplerr"
 	"github.com/stretchr/testify/require"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -77,3 +81,53 @@ func TestRoundTripperErrorOnUnderlyingRoundTripper(t *testing.T) {
 	require.Errorf(t, err, "some error")
 
 }
+
+// errReader is a body which fails to be read
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestRoundTripperFieldViolations(t *testing.T) {
+	server := httptest.NewServer(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return simplerr.Invalid().Field("email", "must be a valid address").Field("age", "must be at least 18")
+	}).Adapter(WithErrorHandler(JSONErrorHandler)))
+	defer server.Close()
+
+	client := &http.Client{Transport: EnableHTTPStatusErrors(http.DefaultTransport)}
+	_, err := client.Get(server.URL) // nolint: bodyclose
+	require.True(t, simplerr.HasErrorCode(err, simplerr.CodeInvalidArgument))
+	require.Equal(t, []simplerr.FieldViolation{
+		{Field: "email", Description: "must be a valid address"},
+		{Field: "age", Description: "must be at least 18"},
+	}, simplerr.GetFieldViolations(err))
+
+	body, readErr := io.ReadAll(GetHTTPResponseAttr(err).Body)
+	require.NoError(t, readErr)
+	require.Contains(t, string(body), `"violations"`, "the body can still be read")
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        io.Reader
+	}{
+		{name: "not JSON", contentType: "text/plain", body: strings.NewReader(`{"violations":[{"field":"email"}]}`)},
+		{name: "invalid JSON", contentType: "application/json; charset=utf-8", body: strings.NewReader(`{`)},
+		{name: "unreadable body", contentType: "application/json", body: errReader{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := EnableHTTPStatusErrors(dummyTransport{
+				response: &http.Response{
+					StatusCode: http.StatusUnprocessableEntity,
+					Header:     http.Header{"Content-Type": []string{tc.contentType}},
+					Body:       io.NopCloser(tc.body),
+				},
+			})
+			_, err := rt.RoundTrip(nil) // nolint: bodyclose
+			require.True(t, simplerr.HasErrorCode(err, simplerr.CodeInvalidArgument))
+			require.Nil(t, simplerr.GetFieldViolations(err))
+		})
+	}
+}
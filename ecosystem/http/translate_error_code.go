@@ -10,6 +10,10 @@ import (
 // HTTPStatus is the HTTP status code
 type HTTPStatus = int
 
+// StatusClientClosedRequest is the non-standard status used when the client closes the request (ie. cancels it)
+// before the server responds
+const StatusClientClosedRequest HTTPStatus = 499
+
 var (
 	mapping map[simplerr.Code]HTTPStatus
 
@@ -27,6 +31,7 @@ func DefaultMapping() map[simplerr.Code]HTTPStatus {
 		simplerr.CodeUnknown:           http.StatusInternalServerError,
 		simplerr.CodeNotFound:          http.StatusNotFound,
 		simplerr.CodeDeadlineExceeded:  http.StatusRequestTimeout,
+		simplerr.CodeCanceled:          StatusClientClosedRequest,
 		simplerr.CodePermissionDenied:  http.StatusForbidden,
 		simplerr.CodeUnauthenticated:   http.StatusUnauthorized,
 		simplerr.CodeNotImplemented:    http.StatusNotImplemented,
@@ -35,6 +40,7 @@ func DefaultMapping() map[simplerr.Code]HTTPStatus {
 		simplerr.CodeUnavailable:       http.StatusServiceUnavailable,
 		simplerr.CodeMissingParameter:  http.StatusUnprocessableEntity,
 		simplerr.CodeResourceExhausted: http.StatusTooManyRequests,
+		simplerr.CodeInternal:          http.StatusInternalServerError,
 	}
 	return m
 }
@@ -45,6 +51,7 @@ func DefaultInverseMapping() map[HTTPStatus]simplerr.Code {
 		http.StatusInternalServerError: simplerr.CodeUnknown,
 		http.StatusNotFound:            simplerr.CodeNotFound,
 		http.StatusRequestTimeout:      simplerr.CodeDeadlineExceeded,
+		StatusClientClosedRequest:      simplerr.CodeCanceled,
 		http.StatusForbidden:           simplerr.CodePermissionDenied,
 		http.StatusUnauthorized:        simplerr.CodeUnauthenticated,
 		http.StatusNotImplemented:      simplerr.CodeNotImplemented,
@@ -97,10 +104,15 @@ func init() {
 // If the error contains a SimpleError, then the status is determined by the mapping.
 // If the error is not a SimpleError then the default error status code will be set.
 // If the error is nil, then no status will be set.
+// If the response has already been written by the handler or by an error handler of another middleware layer (see
+// ApplyMiddleware), the status is not set again and the status that was written is returned.
 func SetStatus(r http.ResponseWriter, err error) HTTPStatus {
 	if err == nil {
 		return 0
 	}
+	if status, written := writtenStatus(r); written {
+		return status
+	}
 	httpStatus, ok := GetStatus(err)
 	if !ok {
 		httpStatus = defaultErrorStatus
@@ -110,22 +122,32 @@ func SetStatus(r http.ResponseWriter, err error) HTTPStatus {
 }
 
 // GetStatus returns the HTTP status that the error maps to if the provided error is a SimpleError.
+// The status is resolved from the error's code (see `simplerr.GetCode()`) using the mapping, and then the HTTPStatus
+// defined in the simplerr registry's CodeMetadata. If neither has a status for the code, the tree of errors is searched
+// for the first code that does.
+// Errors without a code that are recognized by the simplerr classifiers (eg. `context.Canceled` or `os.ErrNotExist`)
+// map to the status of their classified code, even if they are not SimpleErrors.
 // If a mapping could not be found or the error is nil, then the boolean second argument is returned as false
 func GetStatus(err error) (status HTTPStatus, found bool) {
 	if err == nil {
 		return 0, false
 	}
 
-	// Check if the error is a SimpleError
-	serr := simplerr.As(err)
-	if serr == nil {
-		return 0, false
+	// The error's own code takes precedence over the codes of the errors it wraps
+	if code := simplerr.GetCode(err); code != simplerr.CodeUnknown {
+		if httpCode, ok := mapping[code]; ok {
+			return httpCode, true
+		}
+		if meta, ok := simplerr.GetRegistry().CodeMetadata(code); ok && meta.HTTPStatus != 0 {
+			return meta.HTTPStatus, true
+		}
 	}
 
-	// Check if the error has any of the codes in its chain of errors
-	code, ok := simplerr.HasErrorCodes(serr, simplerrCodes...)
+	// Check if the error has any of the codes in its tree of errors, this includes any joined errors
+	code, ok := simplerr.HasErrorCodes(err, simplerrCodes...)
 	if !ok {
-		return 0, false
+		// Fall back to the HTTP status defined in the simplerr registry
+		return registryStatus(err)
 	}
 
 	// Get the HTTP code, this lookup should never fail because the every item in simplerrCodes will have an
@@ -135,6 +157,24 @@ func GetStatus(err error) (status HTTPStatus, found bool) {
 	return httpCode, true
 }
 
+// registryStatus returns the HTTP status defined in the CodeMetadata of the simplerr registry for the first code in
+// the error tree that has one.
+func registryStatus(err error) (status HTTPStatus, found bool) {
+	metadata := simplerr.GetRegistry().ErrorCodeMetadata()
+	var codes []simplerr.Code
+	for c, meta := range metadata {
+		if meta.HTTPStatus != 0 {
+			codes = append(codes, c)
+		}
+	}
+
+	code, ok := simplerr.HasErrorCodes(err, codes...)
+	if !ok {
+		return 0, false
+	}
+	return metadata[code].HTTPStatus, true
+}
+
 // GetCode gets the simplerror Code that corresponds to the HTTPStatus. It returns CodeUnknown if it cannot map the status.
 func GetCode(status HTTPStatus) (code simplerr.Code, found bool) {
 	code, ok := inverseMapping[status]
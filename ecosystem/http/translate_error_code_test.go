@@ -1,10 +1,13 @@
 package simplehttp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/suite"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/lobocv/simplerr"
@@ -48,6 +51,13 @@ func (s *TestSuite) TestTranslateErrorCode() {
 		{fmt.Errorf("wrapped: %w", simplerr.New("something").Code(simplerr.CodeUnauthenticated)), http.StatusUnauthorized, true},
 		{fmt.Errorf("opaque: %s", simplerr.New("something").Code(simplerr.CodeUnauthenticated)), http.StatusInternalServerError, false},
 		{simplerr.Wrap(simplerr.New("something").Code(simplerr.CodePermissionDenied)), http.StatusForbidden, true},
+		{errors.Join(simplerr.New("something"), simplerr.New("something").Code(simplerr.CodeNotFound)), http.StatusNotFound, true},
+		{simplerr.Join(fmt.Errorf("something"), simplerr.New("something").Code(simplerr.CodeNotFound)), http.StatusNotFound, true},
+		{context.Canceled, http.StatusRequestTimeout, true},
+		{fmt.Errorf("handler: %w", context.DeadlineExceeded), http.StatusRequestTimeout, true},
+		{simplerr.Wrap(context.DeadlineExceeded).Code(simplerr.CodeNotFound), http.StatusNotFound, true},
+		{fmt.Errorf("open config: %w", os.ErrNotExist), http.StatusNotFound, true},
+		{&http.MaxBytesError{Limit: 10}, http.StatusTooManyRequests, true},
 		{nil, 200, false}, // default code for httptest.ResponseRecorder is 200
 	}
 
@@ -67,6 +77,48 @@ func (s *TestSuite) TestTranslateErrorCode() {
 
 }
 
+func (s *TestSuite) TestDefaultMappingCanceled() {
+	s.Equal(StatusClientClosedRequest, DefaultMapping()[simplerr.CodeCanceled])
+	s.Equal(simplerr.CodeCanceled, DefaultInverseMapping()[StatusClientClosedRequest])
+}
+
+func (s *TestSuite) TestTranslateErrorCodeRegistryFallback() {
+	const CodeCustom = 100
+	r := simplerr.NewRegistry()
+	r.RegisterCode(CodeCustom, simplerr.CodeMetadata{Description: "custom", HTTPStatus: http.StatusTeapot})
+	r.RegisterCode(CodeCustom+1, simplerr.CodeMetadata{Description: "no status"})
+	defaultRegistry := simplerr.GetRegistry()
+	simplerr.SetRegistry(r)
+	defer simplerr.SetRegistry(defaultRegistry)
+
+	status, found := GetStatus(simplerr.New("something").Code(CodeCustom))
+	s.True(found)
+	s.Equal(http.StatusTeapot, status)
+
+	status, found = GetStatus(simplerr.Wrap(simplerr.New("something").Code(CodeCustom)).Code(CodeCustom + 1))
+	s.True(found, "codes without a status are skipped")
+	s.Equal(http.StatusTeapot, status)
+
+	_, found = GetStatus(simplerr.New("something").Code(CodeCustom + 1))
+	s.False(found)
+
+	status, found = GetStatus(simplerr.New("something").Code(simplerr.CodeNotFound))
+	s.True(found, "the mapping takes precedence over the registry")
+	s.Equal(http.StatusNotFound, status)
+
+	status, found = GetStatus(simplerr.Wrap(simplerr.New("something").Code(simplerr.CodeNotFound)).Code(CodeCustom))
+	s.True(found)
+	s.Equal(http.StatusTeapot, status, "the error's own code takes precedence over the codes it wraps")
+
+	status, found = GetStatus(simplerr.Wrap(simplerr.New("something").Code(CodeCustom)).Code(simplerr.CodeNotFound))
+	s.True(found)
+	s.Equal(http.StatusNotFound, status)
+
+	status, found = GetStatus(simplerr.Wrap(simplerr.New("something").Code(simplerr.CodeNotFound)).Code(CodeCustom + 1))
+	s.True(found, "codes without a status fall back to the codes they wrap")
+	s.Equal(http.StatusNotFound, status)
+}
+
 func (s *TestSuite) TestTranslateStatusCode() {
 
 	testCases := []struct {
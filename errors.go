@@ -3,9 +3,15 @@ package simplerr
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"path/filepath"
 	"slices"
+	"time"
 )
 
+// LogStackTrace controls whether a compact stack trace is included when a SimpleError is logged as a `slog.Value`
+var LogStackTrace = false
+
 // attribute is a key value pair for attributes on errors
 type attribute struct {
 	Key, Value interface{}
@@ -18,6 +24,12 @@ type SimpleError struct {
 	parent error
 	// msg is the error message
 	msg string
+	// publicMsg is a message that is safe to show to clients, as opposed to msg which may contain internal details
+	publicMsg string
+	// msgKey is the key of the client-facing message in the Catalog
+	msgKey string
+	// msgArgs are the arguments of the client-facing message in the Catalog
+	msgArgs map[string]interface{}
 	// code is the error code of the error defined in the registry
 	code Code
 	// silent is a flag that signals that this error should be recorded or logged silently on the server side
@@ -30,6 +42,12 @@ type SimpleError struct {
 	benignReason string
 	// retriable is a flag indicating that this error is transient and that the user should retry the operation
 	retriable bool
+	// retryAfter is a hint for how long the user should wait before retrying the operation
+	retryAfter time.Duration
+	// severity is the severity with which the error should be logged
+	severity Severity
+	// violations are the fields of the request that are invalid
+	violations []FieldViolation
 	// auxiliary are auxiliary informational fields that can be attached to the error
 	auxiliary map[string]interface{}
 	// logger is a scoped logger that can be attached to the error
@@ -38,6 +56,12 @@ type SimpleError struct {
 	attr []attribute
 	// stackTrace is the call stack trace for the error
 	rawStackFrames []uintptr
+	// stackTrace is the symbolized call stack trace for errors that were decoded and no longer have raw stack frames
+	stackTrace []Call
+	// template is the template the error was created from, if any
+	template *Template
+	// frozen is a flag that signals that the error can no longer be modified in place, mutators return a copy instead
+	frozen bool
 }
 
 // New creates a new SimpleError from a formatted string
@@ -46,41 +70,195 @@ func New(_fmt string, args ...interface{}) *SimpleError {
 	return &SimpleError{msg: fmt.Sprintf(_fmt, args...), code: CodeUnknown, rawStackFrames: rawFrames}
 }
 
-// Error satisfies the `error` interface. It uses the `simplerr.Formatter` to generate an error string.
+// NewSkip creates a new SimpleError from a formatted string, skipping `skip` additional calls when capturing the
+// stack trace. This is useful for helper functions that create errors, so that the stack trace starts at the caller
+// of the helper. A skip of 0 is equivalent to New().
+func NewSkip(skip int, _fmt string, args ...interface{}) *SimpleError {
+	rawFrames := rawStackFrames(3 + skip)
+	return &SimpleError{msg: fmt.Sprintf(_fmt, args...), code: CodeUnknown, rawStackFrames: rawFrames}
+}
+
+// Clone returns a copy of the error which can be modified without affecting the original. The auxiliary data and
+// attributes and field violations are copied, while the wrapped error, attached logger and stack trace are shared. The copy is never frozen.
+func (e *SimpleError) Clone() *SimpleError {
+	if e == nil {
+		return nil
+	}
+	c := *e
+	c.frozen = false
+	c.auxiliary = maps.Clone(e.auxiliary)
+	c.attr = slices.Clone(e.attr)
+	c.violations = slices.Clone(e.violations)
+	return &c
+}
+
+// Freeze makes the error immutable so that it is safe to share between goroutines, such as errors returned from a
+// cache or a singleflight group. Once frozen, every mutator (eg. `Code()`, `Aux()`, `Benign()`) leaves the error
+// untouched and instead returns a modified copy of it. Because of this, the result of a mutator must always be used
+// on a frozen error. Freeze should be called before the error is shared.
+func (e *SimpleError) Freeze() *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e.frozen = true
+	return e
+}
+
+// mutable returns the error if it can be modified in place, otherwise it returns a copy of the frozen error.
+func (e *SimpleError) mutable() *SimpleError {
+	if e.frozen {
+		return e.Clone()
+	}
+	return e
+}
+
+// Error satisfies the `error` interface. It uses the `simplerr.Formatter` to generate an error string, unless the
+// registry has its own formatter set.
 func (e *SimpleError) Error() string {
-	if parent := e.Unwrap(); parent != nil {
-		return fmt.Sprintf("%s: %s", e.GetMessage(), parent.Error())
+	if e == nil {
+		return "<nil>"
 	}
-	return e.msg
+	if f := GetRegistry().formatter(); f != nil {
+		return f(e)
+	}
+	return Formatter(e)
 }
 
 // Message sets the message text on the error. This message it used to wrap the underlying error, if it exists.
 func (e *SimpleError) Message(msg string, args ...interface{}) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.msg = fmt.Sprintf(msg, args...)
 	return e
 }
 
 // GetMessage gets the error string for this error, exclusive of any wrapped errors.
 func (e *SimpleError) GetMessage() string {
+	if e == nil {
+		return ""
+	}
 	return e.msg
 }
 
+// Public sets a message that is safe to send to clients. Unlike the message, which may contain internal details such
+// as queries or hostnames, the public message is what the HTTP and gRPC ecosystem packages send in responses.
+// See `GetPublicMessage()`.
+func (e *SimpleError) Public(msg string) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
+	e.publicMsg = msg
+	return e
+}
+
+// GetPublicMessage returns the public message set on this error and whether it was set
+func (e *SimpleError) GetPublicMessage() (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	return e.publicMsg, e.publicMsg != ""
+}
+
+// Field adds a violation for a field of the request along with a description of why it is invalid.
+// Errors without a code are given CodeInvalidArgument. See `Invalid()` and `GetFieldViolations()`.
+func (e *SimpleError) Field(name, description string) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
+	e.violations = append(e.violations, FieldViolation{Field: name, Description: description})
+	if e.code == CodeUnknown {
+		e.setCode(CodeInvalidArgument)
+	}
+	return e
+}
+
+// GetFieldViolations returns the field violations added to this error
+func (e *SimpleError) GetFieldViolations() []FieldViolation {
+	if e == nil {
+		return nil
+	}
+	return e.violations
+}
+
+// Localized sets the key of a client-facing message in the registry's Catalog along with the arguments that fill in
+// the `{name}` placeholders of the message. The arguments are given as key-value pairs, like `Aux()`.
+// See `Translate()`.
+//
+//	simplerr.New("order %d not found", id).Code(simplerr.CodeNotFound).Localized("order.not_found", "id", id)
+func (e *SimpleError) Localized(key string, kv ...interface{}) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
+	e.msgKey = key
+	e.msgArgs = map[string]interface{}{}
+	for ii := 0; ii+1 < len(kv); ii += 2 {
+		if k, ok := kv[ii].(string); ok {
+			e.msgArgs[k] = kv[ii+1]
+		}
+	}
+	return e
+}
+
+// GetLocalized returns the message key and arguments set with Localized() and whether they were set
+func (e *SimpleError) GetLocalized() (string, map[string]interface{}, bool) {
+	if e == nil {
+		return "", nil, false
+	}
+	return e.msgKey, e.msgArgs, e.msgKey != ""
+}
+
 // GetCode returns the error code as defined in the registry
 func (e *SimpleError) GetCode() Code {
+	if e == nil {
+		return CodeUnknown
+	}
 	return e.code
 }
 
 // Code sets the error code. The assigned code should be defined in the registry.
+// Errors inherit the default benign, retriable and silent flags of the code, as defined in the registry's CodeMetadata.
 func (e *SimpleError) Code(code Code) *SimpleError {
-	e.code = code
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
+	e.setCode(code)
 	return e
 }
 
+// setCode sets the error code along with its default flags and applies the stack policy
+func (e *SimpleError) setCode(code Code) {
+	e.code = code
+	e.applyCodeDefaults()
+	e.applyStackPolicy()
+}
+
+// applyCodeDefaults sets the default flags of the error's code, as defined in the registry's CodeMetadata
+func (e *SimpleError) applyCodeDefaults() {
+	meta, ok := GetRegistry().CodeMetadata(e.code)
+	if !ok {
+		return
+	}
+	e.benign = e.benign || meta.Benign
+	e.retriable = e.retriable || meta.Retriable
+	e.silent = e.silent || meta.Silent
+}
+
 // Benign marks the error as "benign". A benign error is an error that depends on the context of the caller.
 // eg a NotFoundError is only an error if the caller is expecting the entity to exist.
 // These errors can usually be logged less severely (ie at INFO rather than ERROR level)
 func (e *SimpleError) Benign() *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.benign = true
+	e.applyStackPolicy()
 	return e
 }
 
@@ -89,25 +267,40 @@ func (e *SimpleError) Benign() *SimpleError {
 // eg a NotFoundError is only an error if the caller is expecting the entity to exist
 // These errors can usually be logged less severely (ie at INFO rather than ERROR level)
 func (e *SimpleError) BenignReason(reason string) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.benign = true
 	e.benignReason = reason
+	e.applyStackPolicy()
 	return e
 }
 
 // GetBenignReason returns the benign reason and whether the error was marked as benign
 // ie. This error can be logged at INFO level and then discarded.
 func (e *SimpleError) GetBenignReason() (string, bool) {
+	if e == nil {
+		return "", false
+	}
 	return e.benignReason, e.benign
 }
 
 // GetSilent returns a flag that signals that this error should be recorded or logged silently on the server side
 // ie. This error should not be logged at all
 func (e *SimpleError) GetSilent() bool {
+	if e == nil {
+		return false
+	}
 	return e.silent
 }
 
 // Silence sets the error as silent. Silent errors can be ignored by loggers.
 func (e *SimpleError) Silence() *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.silent = true
 	return e
 }
@@ -115,18 +308,67 @@ func (e *SimpleError) Silence() *SimpleError {
 // GetRetriable returns a flag that signals that the operation which created this error is transient and that the
 // user should retry the operation in hopes of it succeeding.
 func (e *SimpleError) GetRetriable() bool {
+	if e == nil {
+		return false
+	}
 	return e.retriable
 }
 
 // Retriable sets the error as retriable.
 func (e *SimpleError) Retriable() *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
+	e.retriable = true
+	return e
+}
+
+// RetryAfter sets the error as retriable and attaches a hint for how long the user should wait before retrying the
+// operation. The hint takes precedence over the backoff of the retry policy when using `Retry()`.
+func (e *SimpleError) RetryAfter(d time.Duration) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.retriable = true
+	e.retryAfter = d
 	return e
 }
 
+// GetRetryAfter returns the hint for how long the user should wait before retrying the operation and whether the hint
+// was set.
+func (e *SimpleError) GetRetryAfter() (time.Duration, bool) {
+	if e == nil {
+		return 0, false
+	}
+	return e.retryAfter, e.retryAfter > 0
+}
+
+// Severity sets the severity with which the error should be logged. See `GetSeverity()`.
+func (e *SimpleError) Severity(s Severity) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
+	e.severity = s
+	return e
+}
+
+// GetSeverity returns the severity set on this error, or SeverityUnset if it was not set
+func (e *SimpleError) GetSeverity() Severity {
+	if e == nil {
+		return SeverityUnset
+	}
+	return e.severity
+}
+
 // GetAuxiliary gets the auxiliary informational data attached to this error.
 // This key-value data can be attached to structured loggers.
 func (e *SimpleError) GetAuxiliary() map[string]interface{} {
+	if e == nil {
+		return nil
+	}
 	return e.auxiliary
 }
 
@@ -135,6 +377,9 @@ func (e *SimpleError) GetAuxiliary() map[string]interface{} {
 // with simplerr. Much like keys in the `context` package, the `key` should be a custom type so it does
 // not have naming collisions with other values.
 func (e *SimpleError) GetAttribute(key interface{}) (interface{}, bool) {
+	if e == nil {
+		return nil, false
+	}
 	for _, attr := range e.attr {
 		if attr.Key == key {
 			return attr.Value, true
@@ -148,6 +393,10 @@ func (e *SimpleError) GetAttribute(key interface{}) (interface{}, bool) {
 // This auxiliary data can be retrieved by using `ExtractAuxiliary()` and attached to structured loggers.
 // Do not use this to detect any attributes on the error, instead use Attr().`
 func (e *SimpleError) Aux(kv ...interface{}) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	if e.auxiliary == nil {
 		e.auxiliary = map[string]interface{}{}
 	}
@@ -170,6 +419,10 @@ func (e *SimpleError) Aux(kv ...interface{}) *SimpleError {
 // This auxiliary data can be retrieved by using `ExtractAuxiliary()` and attached to structured loggers.
 // Do not use this to detect any attributes on the error, instead use Attr().`
 func (e *SimpleError) AuxMap(aux map[string]interface{}) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	if e.auxiliary == nil {
 		e.auxiliary = map[string]interface{}{}
 	}
@@ -183,34 +436,48 @@ func (e *SimpleError) AuxMap(aux map[string]interface{}) *SimpleError {
 // Attr() behaves similarly to `context.WithValue()`. Keys should be custom types in order to avoid naming collisions.
 // Use `GetAttribute()` to get the value of the attribute.
 func (e *SimpleError) Attr(key, value interface{}) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.attr = append(e.attr, attribute{Key: key, Value: value})
 	return e
 }
 
 // Logger attaches a structured logger to the error
 func (e *SimpleError) Logger(l *slog.Logger) *SimpleError {
+	if e == nil {
+		return nil
+	}
+	e = e.mutable()
 	e.logger = l
 	return e
 }
 
 // GetLogger returns a structured logger with auxiliary information preset
-// It uses the first found attached structured logger and otherwise uses the default logger
+// It uses the first found attached structured logger and otherwise uses the default logger.
+// The entire tree of errors is searched, including errors combined with `errors.Join()` or `simplerr.Join()`.
 func (e *SimpleError) GetLogger() *slog.Logger {
+	if e == nil {
+		return slog.Default()
+	}
 	l := e.logger
 
 	fields := make([]any, 0, 2*len(e.auxiliary))
-	var errInChain = e
-	for errInChain != nil {
-		if l == nil && errInChain.logger != nil {
-			l = errInChain.logger
+	walk(e, func(err error) bool {
+		errInTree, ok := err.(*SimpleError)
+		if !ok {
+			return false
+		}
+		if l == nil && errInTree.logger != nil {
+			l = errInTree.logger
 		}
 
-		for k, v := range errInChain.GetAuxiliary() {
+		for k, v := range errInTree.GetAuxiliary() {
 			fields = append(fields, v, k) // append backwards because of the slice reversal
 		}
-
-		errInChain = As(errInChain.Unwrap())
-	}
+		return false
+	})
 	// Reverse the slice so that the aux values at the top of the stack take precedent over the lower ones
 	// For cases where there are conflicting keys
 	slices.Reverse(fields)
@@ -222,23 +489,109 @@ func (e *SimpleError) GetLogger() *slog.Logger {
 	return l.With(fields...)
 }
 
+// LogValue implements the `slog.LogValuer` interface so that errors passed to structured loggers,
+// eg. `slog.Any("err", err)`, are logged as a group of fields rather than a flat error string.
+// The group contains the error string, the code of the error chain with its name, description, namespace and doc URL,
+// the benign, retriable and silent flags, the auxiliary data of the entire chain and optionally (see `LogStackTrace`) a compact stack trace.
+func (e *SimpleError) LogValue() slog.Value {
+	if e == nil {
+		return slog.AnyValue(nil)
+	}
+	code := codeOf(e)
+	reason, benign := IsBenign(e)
+
+	attrs := make([]slog.Attr, 0, 12)
+	attrs = append(attrs,
+		slog.String("message", e.Error()),
+		slog.Int("code", int(code)),
+		slog.String("code_name", code.String()),
+		slog.String("code_description", GetRegistry().CodeDescription(code)),
+	)
+	if namespace := GetRegistry().CodeNamespace(code); namespace != "" {
+		attrs = append(attrs, slog.String("code_namespace", namespace))
+	}
+	if docURL := GetRegistry().CodeDocURL(code); docURL != "" {
+		attrs = append(attrs, slog.String("code_doc_url", docURL))
+	}
+	attrs = append(attrs, slog.Bool("benign", benign))
+	if reason != "" {
+		attrs = append(attrs, slog.String("benign_reason", reason))
+	}
+	attrs = append(attrs,
+		slog.Bool("retriable", IsRetriable(e)),
+		slog.Bool("silent", IsSilent(e)),
+	)
+
+	if violations := GetFieldViolations(e); len(violations) > 0 {
+		violationAttrs := make([]slog.Attr, 0, len(violations))
+		for _, v := range violations {
+			violationAttrs = append(violationAttrs, slog.String(v.Field, v.Description))
+		}
+		attrs = append(attrs, slog.Attr{Key: "field_violations", Value: slog.GroupValue(violationAttrs...)})
+	}
+
+	if aux := ExtractAuxiliary(e); len(aux) > 0 {
+		auxAttrs := make([]slog.Attr, 0, len(aux))
+		for _, k := range sortedKeys(aux) {
+			auxAttrs = append(auxAttrs, slog.Any(k, aux[k]))
+		}
+		attrs = append(attrs, slog.Attr{Key: "aux", Value: slog.GroupValue(auxAttrs...)})
+	}
+
+	if LogStackTrace {
+		stack := e.StackTrace()
+		frames := make([]string, 0, len(stack))
+		for _, call := range stack {
+			frames = append(frames, fmt.Sprintf("%s:%d %s", filepath.Base(call.File), call.Line, call.FuncName))
+		}
+		attrs = append(attrs, slog.Any("stack", frames))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
 // GetDescription returns the description of the error code on the error.
 func (e *SimpleError) GetDescription() string {
-	return registry.CodeDescription(e.code)
+	if e == nil {
+		return ""
+	}
+	return GetRegistry().CodeDescription(e.code)
 }
 
 // StackTrace returns the stack trace at the point at which the error was raised.
 func (e *SimpleError) StackTrace() []Call {
+	if e == nil {
+		return nil
+	}
+	if e.rawStackFrames == nil {
+		return e.stackTrace
+	}
 	return stackTrace(e.rawStackFrames)
 }
 
 // StackFrames returns a slice of pointers to program counters
 // This method is primarily used to better integrate with sentry stack trace extraction
 func (e *SimpleError) StackFrames() []uintptr {
+	if e == nil {
+		return nil
+	}
 	return e.rawStackFrames
 }
 
+// Is reports whether the error was created from the target Template. This allows `errors.Is(err, template)` to
+// match any instance created from the template.
+func (e *SimpleError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	t, ok := target.(*Template)
+	return ok && e.template != nil && e.template == t
+}
+
 // Unwrap implement the interface required for error unwrapping. It returns the underlying (wrapped) error.
 func (e *SimpleError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
 	return e.parent
 }
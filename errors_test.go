@@ -2,12 +2,15 @@ package simplerr
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -173,6 +176,13 @@ func (s *TestSuite) TestHasErrorCodes() {
 		s.False(ok)
 		s.Zero(c)
 	})
+
+	s.Run("get code", func() {
+		s.Equal(CodeNotFound, GetCode(Wrapf(New("not found").Code(CodeNotFound), "wrapped")))
+		s.Equal(CodeCanceled, GetCode(fmt.Errorf("query: %w", context.Canceled)), "errors without a code are classified")
+		s.Equal(CodeUnknown, GetCode(New("unknown")))
+		s.Equal(CodeUnknown, GetCode(nil))
+	})
 }
 
 func (s *TestSuite) TestBenign() {
@@ -341,6 +351,49 @@ func (s *TestSuite) TestRetriable() {
 	})
 }
 
+func (s *TestSuite) TestPublicMessage() {
+	internal := New("SELECT * FROM users WHERE id = 1: no rows").Code(CodeNotFound).Public("user not found")
+
+	msg, ok := internal.GetPublicMessage()
+	s.True(ok)
+	s.Equal("user not found", msg)
+	s.Equal("SELECT * FROM users WHERE id = 1: no rows", internal.Error(), "the error string keeps the internal message")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+		found    bool
+	}{
+		{"public message", internal, "user not found", true},
+		{"wrapped", Wrapf(internal, "host db.example.internal"), "user not found", true},
+		{"nearest wins", Wrap(internal).Public("account not found"), "account not found", true},
+		{"stdlib wrapped", fmt.Errorf("wrapped: %w", internal), "user not found", true},
+		{"joined", errors.Join(New("other"), internal), "user not found", true},
+		{"code description", New("connection refused").Code(CodeUnavailable), "unavailable", false},
+		{"classified", fmt.Errorf("query: %w", context.DeadlineExceeded), "deadline exceeded", false},
+		{"no code", fmt.Errorf("secret"), "unknown", false},
+		{"template", Define(CodeNotFound, "internal").Public("not here").New(), "not here", true},
+	}
+	for _, tc := range testCases {
+		msg, ok := GetPublicMessage(tc.err)
+		s.Equal(tc.found, ok, tc.name)
+		s.Equal(tc.expected, msg, tc.name)
+	}
+
+	s.Run("encoding", func() {
+		s.Contains(fmt.Sprintf("%+v", internal), "[0] SELECT * FROM users WHERE id = 1: no rows\n    public: user not found\n")
+
+		data, err := json.Marshal(internal)
+		s.Require().NoError(err)
+		got := &SimpleError{}
+		s.Require().NoError(json.Unmarshal(data, got))
+		msg, ok := got.GetPublicMessage()
+		s.True(ok)
+		s.Equal("user not found", msg)
+	})
+}
+
 func (s *TestSuite) TestAuxiliaryFields() {
 	serr := New("something").Aux("one", 1, "two", 2.0, "three", "THREE")
 	expected := map[string]interface{}{
@@ -465,6 +518,96 @@ func (s *TestSuite) TestErrorCodeDescriptions() {
 	s.Equal("not supported", serr.GetDescription())
 }
 
+func (s *TestSuite) TestCodeNames() {
+	s.Run("default codes have names", func() {
+		s.Equal("NOT_FOUND", CodeNotFound.String())
+		s.Equal("UNKNOWN", CodeUnknown.String())
+		s.Equal("code=PERMISSION_DENIED", fmt.Sprintf("code=%s", CodePermissionDenied))
+		s.Equal("1000", Code(1000).String(), "unregistered codes are rendered as numbers")
+
+		code, ok := GetRegistry().Lookup("UNAVAILABLE")
+		s.True(ok)
+		s.Equal(CodeUnavailable, code)
+		_, ok = GetRegistry().Lookup("unavailable")
+		s.False(ok)
+	})
+
+	s.Run("text marshaling", func() {
+		type config struct {
+			Code  Code            `json:"code"`
+			Codes map[Code]string `json:"codes"`
+		}
+		data, err := json.Marshal(config{Code: CodeNotFound, Codes: map[Code]string{CodeCanceled: "retry", 1000: "other"}})
+		s.Require().NoError(err)
+		s.JSONEq(`{"code": "NOT_FOUND", "codes": {"CANCELED": "retry", "1000": "other"}}`, string(data))
+
+		var got config
+		s.Require().NoError(json.Unmarshal(data, &got))
+		s.Equal(CodeNotFound, got.Code)
+		s.Equal(map[Code]string{CodeCanceled: "retry", 1000: "other"}, got.Codes)
+
+		s.Error(json.Unmarshal([]byte(`{"code": "NOT_A_CODE"}`), &got))
+		s.Error(json.Unmarshal([]byte(`{"code": true}`), &got))
+	})
+
+	s.Run("decode numeric codes", func() {
+		// Codes were encoded as numbers before they were given names
+		type config struct {
+			Code  Code            `json:"code"`
+			Codes map[Code]string `json:"codes"`
+		}
+		var got config
+		s.Require().NoError(json.Unmarshal([]byte(`{"code": 2, "codes": {"12": "retry", "1000": "other"}}`), &got))
+		s.Equal(config{Code: CodeNotFound, Codes: map[Code]string{CodeCanceled: "retry", 1000: "other"}}, got)
+
+		s.Require().NoError(json.Unmarshal([]byte(`{"code": "11"}`), &got))
+		s.Equal(CodeDeadlineExceeded, got.Code)
+
+		s.Require().NoError(json.Unmarshal([]byte(`{"code": null}`), &got))
+		s.Equal(CodeDeadlineExceeded, got.Code, "null leaves the code unchanged")
+	})
+
+	s.Run("parse codes", func() {
+		code, err := ParseCode("DEADLINE_EXCEEDED")
+		s.NoError(err)
+		s.Equal(CodeDeadlineExceeded, code)
+
+		code, err = ParseCode("11")
+		s.NoError(err)
+		s.Equal(CodeDeadlineExceeded, code)
+
+		_, err = ParseCode("deadline exceeded")
+		s.True(HasErrorCode(err, CodeInvalidArgument))
+	})
+
+	s.Run("custom code names", func() {
+		r := NewRegistry()
+		r.RegisterCode(100, CodeMetadata{Name: "BILLING_CARD_DECLINED", Description: "card declined"})
+		r.RegisterCode(101, CodeMetadata{Description: "no name"})
+		defaultRegistry := GetRegistry()
+		SetRegistry(r)
+		defer SetRegistry(defaultRegistry)
+
+		s.Equal("BILLING_CARD_DECLINED", Code(100).String())
+		s.Equal("101", Code(101).String())
+		s.Equal("2", CodeNotFound.String(), "the custom registry does not have the default codes")
+		code, ok := r.Lookup("BILLING_CARD_DECLINED")
+		s.True(ok)
+		s.Equal(Code(100), code)
+
+		s.Panics(func() {
+			r.RegisterCode(102, CodeMetadata{Name: "BILLING_CARD_DECLINED"})
+		}, "names must be unique")
+		for _, name := range []string{"lower", "1LEADING_DIGIT", "_LEADING_UNDERSCORE", "HAS SPACE", "HAS-DASH"} {
+			s.Panics(func() {
+				r.RegisterCode(103, CodeMetadata{Name: name})
+			}, name)
+		}
+		_, registered := r.CodeMetadata(103)
+		s.False(registered, "codes with invalid names are not registered")
+	})
+}
+
 func (s *TestSuite) TestCustomRegistry() {
 	r := NewRegistry()
 	const CodeCustom = 100
@@ -506,6 +649,101 @@ func (s *TestSuite) TestCustomRegistry() {
 
 }
 
+func (s *TestSuite) TestCodeMetadata() {
+	r := NewRegistry()
+	const (
+		CodeFlaky   = 100
+		CodeMissing = 101
+	)
+	r.RegisterCode(CodeFlaky, CodeMetadata{
+		Description: "flaky",
+		Retriable:   true,
+		Severity:    SeverityWarn,
+		DocURL:      "https://example.com/errors/flaky",
+		HTTPStatus:  503,
+		GRPCCode:    14,
+	})
+	r.RegisterCode(CodeMissing, CodeMetadata{Description: "missing", Benign: true, Silent: true})
+	defaultRegistry := GetRegistry()
+	SetRegistry(r)
+	defer SetRegistry(defaultRegistry)
+
+	s.Run("get metadata", func() {
+		meta, ok := r.CodeMetadata(CodeFlaky)
+		s.True(ok)
+		s.Equal("flaky", meta.Description)
+		s.Equal(SeverityWarn, meta.Severity)
+		s.Equal("https://example.com/errors/flaky", meta.DocURL)
+		s.Equal(503, meta.HTTPStatus)
+		s.Equal(uint32(14), meta.GRPCCode)
+
+		_, ok = r.CodeMetadata(CodeNotFound)
+		s.False(ok)
+
+		s.Len(r.ErrorCodeMetadata(), 2)
+		s.Equal(map[Code]string{CodeFlaky: "flaky", CodeMissing: "missing"}, r.ErrorCodes())
+	})
+
+	s.Run("errors inherit the default flags of the code", func() {
+		serr := New("something").Code(CodeFlaky)
+		_, benign := serr.GetBenignReason()
+		s.True(serr.GetRetriable())
+		s.False(benign)
+		s.False(serr.GetSilent())
+
+		serr = New("something").Code(CodeMissing)
+		_, benign = serr.GetBenignReason()
+		s.True(benign)
+		s.True(serr.GetSilent())
+		s.False(serr.GetRetriable())
+	})
+
+	s.Run("doc url is exposed", func() {
+		serr := New("something").Code(CodeFlaky)
+		s.Equal("https://example.com/errors/flaky", r.CodeDocURL(CodeFlaky))
+		s.Equal("", r.CodeDocURL(CodeMissing))
+
+		s.Contains(fmt.Sprintf("%+v", serr), "code: 100 (flaky)\n    doc: https://example.com/errors/flaky\n")
+
+		data, err := json.Marshal(serr)
+		s.Require().NoError(err)
+		raw := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(data, &raw))
+		s.Equal("https://example.com/errors/flaky", raw["code_doc_url"])
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&output, nil))
+		logger.Info("failed", "err", serr)
+		logged := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(output.Bytes(), &logged))
+		s.Equal("https://example.com/errors/flaky", logged["err"].(map[string]interface{})["code_doc_url"]) // nolint: errcheck
+	})
+
+	s.Run("default flags do not unset flags already set", func() {
+		serr := New("something").Retriable().Code(CodeMissing)
+		_, benign := serr.GetBenignReason()
+		s.True(serr.GetRetriable())
+		s.True(benign)
+	})
+
+	s.Run("templates inherit the default flags of the code", func() {
+		tmpl := Define(CodeFlaky, "flaky operation")
+		s.True(tmpl.New().GetRetriable())
+	})
+
+	s.Run("unregistered codes have no default flags", func() {
+		serr := New("something").Code(CodeMissing + 1)
+		s.False(serr.GetSilent())
+		s.False(serr.GetRetriable())
+	})
+
+	s.Run("default codes have no default flags", func() {
+		meta, ok := defaultRegistry.CodeMetadata(CodeNotFound)
+		s.True(ok)
+		s.Equal(CodeMetadata{Name: "NOT_FOUND", Description: "not found"}, meta)
+	})
+}
+
 func (s *TestSuite) TestErrorFormatting() {
 	original := fmt.Errorf("original")
 	serr1 := Wrapf(original, "wrapper %d", 1)
@@ -661,3 +899,286 @@ func First() *SimpleError {
 	e := Second()
 	return Wrapf(e, "first wrapper")
 }
+
+func (s *TestSuite) TestLogValue() {
+	original := fmt.Errorf("original")
+	serr1 := Wrapf(original, "wrapper 1").Code(CodeNotFound).BenignReason("expected").Aux("b", 2, "a", 1)
+	serr2 := Wrapf(serr1, "wrapper 2").Retriable().Aux("a", 10)
+
+	s.Run("structured group", func() {
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		logger.Info("failed", "err", serr2)
+
+		s.Equal(`level=INFO msg=failed err.message="wrapper 2: wrapper 1: original" err.code=2 err.code_name=NOT_FOUND `+
+			`err.code_description="not found" err.benign=true err.benign_reason=expected err.retriable=true `+
+			`err.silent=false err.aux.a=10 err.aux.b=2`+"\n", output.String())
+	})
+
+	s.Run("JSON handler", func() {
+		var output bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&output, nil))
+		logger.Error("failed", slog.Any("err", New("something").Silence()))
+
+		got := map[string]any{}
+		s.Require().NoError(json.NewDecoder(&output).Decode(&got))
+		s.Equal(map[string]any{
+			"message":          "something",
+			"code":             float64(CodeUnknown),
+			"code_name":        "UNKNOWN",
+			"code_description": "unknown",
+			"benign":           false,
+			"retriable":        false,
+			"silent":           true,
+		}, got["err"])
+	})
+
+	s.Run("with stack trace", func() {
+		LogStackTrace = true
+		defer func() { LogStackTrace = false }()
+
+		value := serr2.LogValue()
+		var stack []string
+		for _, attr := range value.Group() {
+			if attr.Key == "stack" {
+				stack = attr.Value.Any().([]string) // nolint: errcheck
+			}
+		}
+		s.Require().NotEmpty(stack)
+		s.Contains(stack[0], "errors_test.go")
+		s.Contains(stack[0], "TestLogValue")
+	})
+}
+
+func (s *TestSuite) TestJoinedErrors() {
+	notFound := New("not found").Code(CodeNotFound).Aux("id", 1, "shared", "not_found")
+	benign := New("benign").BenignReason("expected").Aux("shared", "benign").Attr(1, "benign")
+	retriable := New("retriable").Retriable().Silence().Attr(1, "retriable")
+	plain := fmt.Errorf("plain")
+
+	s.Run("stdlib join", func() {
+		err := fmt.Errorf("wrapped: %w", errors.Join(plain, notFound, benign, retriable))
+
+		s.True(HasErrorCode(err, CodeNotFound))
+		code, ok := HasErrorCodes(err, CodePermissionDenied, CodeNotFound)
+		s.True(ok)
+		s.Equal(CodeNotFound, code)
+
+		reason, isBenign := IsBenign(err)
+		s.True(isBenign)
+		s.Equal("expected", reason)
+		s.True(IsRetriable(err))
+		s.True(IsSilent(err))
+
+		s.Equal(map[string]interface{}{"id": 1, "shared": "not_found"}, ExtractAuxiliary(err),
+			"errors joined earlier take precedence")
+
+		v, ok := GetAttribute(err, 1)
+		s.True(ok)
+		s.Equal("benign", v, "errors joined earlier take precedence")
+	})
+
+	s.Run("simplerr join", func() {
+		err := Join(nil, plain, notFound).Code(CodeUnavailable).Aux("shared", "joined")
+
+		s.Equal("plain\nnot found", err.Error())
+		s.True(errors.Is(err, plain))
+		s.True(errors.Is(err, notFound))
+
+		code, ok := HasErrorCodes(err, CodeNotFound, CodeUnavailable)
+		s.True(ok)
+		s.Equal(CodeUnavailable, code, "the joining error takes precedence over the errors it joins")
+		s.True(HasErrorCode(err, CodeNotFound))
+		s.Equal(map[string]interface{}{"id": 1, "shared": "joined"}, ExtractAuxiliary(err))
+		s.Equal("(*TestSuite).TestJoinedErrors.func2", err.StackTrace()[0].FuncName)
+	})
+
+	s.Run("join nil errors", func() {
+		s.Nil(Join())
+		s.Nil(Join(nil, nil))
+	})
+
+	s.Run("nil join assigned to an error interface", func() {
+		joinErrors := func(errs ...error) error {
+			if err := Join(errs...); err != nil {
+				return err
+			}
+			return nil
+		}
+		s.NoError(joinErrors(nil, nil))
+		s.Error(joinErrors(nil, plain))
+
+		var err error = Join(nil, nil)
+		s.True(err != nil, "a nil *SimpleError is not a nil error interface") // nolint: staticcheck
+	})
+
+	s.Run("methods on a nil join", func() {
+		e := Join(nil, nil)
+		s.Nil(e.Code(CodeUnavailable).Aux("id", 1).AuxMap(map[string]interface{}{"k": "v"}).Message("msg").
+			Public("public").Field("name", "required").Localized("key").Benign().BenignReason("reason").Silence().
+			Retriable().RetryAfter(time.Second).Severity(SeverityWarn).Attr(1, 1).Logger(slog.Default()).
+			Clone().Freeze())
+
+		s.Equal("<nil>", e.Error())
+		s.Equal("<nil>", fmt.Sprintf("%+v", e))
+		s.Equal("", e.GetMessage())
+		s.Equal(CodeUnknown, e.GetCode())
+		s.Equal(SeverityUnset, e.GetSeverity())
+		s.Equal("", e.GetDescription())
+		s.False(e.GetSilent())
+		s.False(e.GetRetriable())
+		s.False(e.Is(plain))
+		s.Nil(e.GetFieldViolations())
+		s.Nil(e.GetAuxiliary())
+		s.Nil(e.StackTrace())
+		s.Nil(e.StackFrames())
+		s.Nil(e.Unwrap())
+		s.Equal(slog.Default(), e.GetLogger())
+		s.Equal(slog.KindAny, e.LogValue().Kind())
+		_, ok := e.GetPublicMessage()
+		s.False(ok)
+		_, _, ok = e.GetLocalized()
+		s.False(ok)
+		_, ok = e.GetBenignReason()
+		s.False(ok)
+		_, ok = e.GetRetryAfter()
+		s.False(ok)
+		_, ok = e.GetAttribute(1)
+		s.False(ok)
+		b, err := e.MarshalJSON()
+		s.NoError(err)
+		s.Equal("null", string(b))
+
+		var wrapped error = e
+		s.False(HasErrorCode(wrapped, CodeUnavailable))
+		s.False(IsSilent(wrapped))
+	})
+
+	s.Run("logger picks up joined errors", func() {
+		output := setupTestLogger()
+		err := Join(notFound, benign)
+		err.GetLogger().Info("joined")
+
+		got := map[string]any{}
+		s.Require().NoError(json.NewDecoder(output).Decode(&got))
+		s.Equal(float64(1), got["id"])
+	})
+
+	s.Run("verbose formatting visits joined errors", func() {
+		got := fmt.Sprintf("%+v", Join(notFound, plain))
+		s.Contains(got, "\n[1] *errors.joinError: not found\nplain\n[2] not found\n    code: 2 NOT_FOUND (not found)")
+		s.Contains(got, "\n[3] *errors.errorString: plain")
+	})
+
+	s.Run("JSON round trip", func() {
+		data, err := json.Marshal(Join(plain, notFound).Code(CodeUnavailable))
+		s.Require().NoError(err)
+
+		got := &SimpleError{}
+		s.Require().NoError(json.Unmarshal(data, got))
+		s.Equal("plain\nnot found", got.Error())
+		s.True(HasErrorCode(got, CodeNotFound))
+		s.True(HasErrorCode(got, CodeUnavailable))
+		s.Equal(map[string]interface{}{"id": float64(1), "shared": "not_found"}, ExtractAuxiliary(got))
+	})
+}
+
+func (s *TestSuite) TestClone() {
+	original := New("original").Code(CodeNotFound).Aux("one", 1).Attr(1, "one")
+	clone := original.Clone().Code(CodeUnavailable).Aux("two", 2).Attr(2, "two").Message("clone")
+
+	s.Equal(CodeNotFound, original.GetCode())
+	s.Equal("original", original.Error())
+	s.Equal(map[string]interface{}{"one": 1}, original.GetAuxiliary())
+	_, ok := original.GetAttribute(2)
+	s.False(ok)
+
+	s.Equal(CodeUnavailable, clone.GetCode())
+	s.Equal("clone", clone.Error())
+	s.Equal(map[string]interface{}{"one": 1, "two": 2}, clone.GetAuxiliary())
+	v, ok := clone.GetAttribute(1)
+	s.True(ok)
+	s.Equal("one", v)
+	s.Equal(original.StackFrames(), clone.StackFrames())
+}
+
+func (s *TestSuite) TestFreeze() {
+	logger := slog.Default()
+	frozen := Wrapf(fmt.Errorf("original"), "frozen").Code(CodeNotFound).Aux("one", 1).Freeze()
+
+	derived := frozen.
+		Message("derived").
+		Code(CodeUnavailable).
+		Benign().
+		BenignReason("reason").
+		Silence().
+		Retriable().
+		Aux("two", 2).
+		AuxMap(map[string]interface{}{"three": 3}).
+		Attr(1, "one").
+		Logger(logger)
+
+	s.Run("frozen error is not modified", func() {
+		s.Equal("frozen: original", frozen.Error())
+		s.Equal(CodeNotFound, frozen.GetCode())
+		_, benign := frozen.GetBenignReason()
+		s.False(benign)
+		s.False(frozen.GetSilent())
+		s.False(frozen.GetRetriable())
+		s.Equal(map[string]interface{}{"one": 1}, frozen.GetAuxiliary())
+		_, ok := frozen.GetAttribute(1)
+		s.False(ok)
+		s.Nil(frozen.logger)
+	})
+
+	s.Run("mutators return a modified copy", func() {
+		s.NotSame(frozen, derived)
+		s.Equal("derived: original", derived.Error())
+		s.Equal(CodeUnavailable, derived.GetCode())
+		reason, benign := derived.GetBenignReason()
+		s.True(benign)
+		s.Equal("reason", reason)
+		s.True(derived.GetSilent())
+		s.True(derived.GetRetriable())
+		s.Equal(map[string]interface{}{"one": 1, "two": 2, "three": 3}, derived.GetAuxiliary())
+		v, ok := derived.GetAttribute(1)
+		s.True(ok)
+		s.Equal("one", v)
+		s.Equal(logger, derived.logger)
+		s.Equal(frozen.StackFrames(), derived.StackFrames())
+	})
+
+	s.Run("the copy is not frozen", func() {
+		again := derived.Aux("four", 4)
+		s.Same(derived, again)
+	})
+
+	s.Run("concurrent use of a frozen error", func() {
+		shared := New("shared").Aux("one", 1).Attr(1, "one").Freeze()
+
+		var wg sync.WaitGroup
+		for ii := 0; ii < 20; ii++ {
+			wg.Add(1)
+			go func(ii int) {
+				defer wg.Done()
+				derived := shared.Aux("goroutine", ii).Attr(2, ii).Code(CodeUnavailable).Benign()
+				s.Equal(ii, derived.GetAuxiliary()["goroutine"])
+				_ = shared.Error()
+				_ = ExtractAuxiliary(shared)
+				_, _ = GetAttribute(shared, 1)
+				_ = fmt.Sprintf("%+v", shared)
+			}(ii)
+		}
+		wg.Wait()
+
+		s.Equal(map[string]interface{}{"one": 1}, shared.GetAuxiliary())
+		s.Equal(CodeUnknown, shared.GetCode())
+	})
+}
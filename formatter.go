@@ -0,0 +1,157 @@
+package simplerr
+
+import (
+	"fmt"
+	"io"
+)
+
+// ErrorFormatter is a function that generates the error string for a SimpleError.
+// The formatter is responsible for rendering the error's message and any wrapped errors.
+type ErrorFormatter func(e *SimpleError) string
+
+// Formatter is the package-level formatter used to generate the string returned by `SimpleError.Error()`.
+// It can be overridden per registry by using `Registry.SetFormatter()`.
+var Formatter ErrorFormatter = DefaultFormatter
+
+// DefaultFormatter separates the error message and the wrapped error with a colon, ie "message: wrapped".
+var DefaultFormatter = SeparatorFormatter(": ")
+
+// NewlineFormatter separates the error message and each wrapped error with a new line.
+var NewlineFormatter = SeparatorFormatter("\n")
+
+// SeparatorFormatter returns an ErrorFormatter that joins the error message and the wrapped error with the given
+// separator. Errors with an empty message do not add a separator, they render as the wrapped error only.
+func SeparatorFormatter(sep string) ErrorFormatter {
+	return func(e *SimpleError) string {
+		parent := e.Unwrap()
+		if parent == nil {
+			return e.GetMessage()
+		}
+		if e.GetMessage() == "" {
+			return parent.Error()
+		}
+		return e.GetMessage() + sep + parent.Error()
+	}
+}
+
+// MessageOnlyFormatter renders only the message of the outermost error, hiding any wrapped errors.
+// If the error has an empty message, the wrapped error is rendered in its place.
+func MessageOnlyFormatter(e *SimpleError) string {
+	if msg := e.GetMessage(); msg != "" {
+		return msg
+	}
+	if parent := e.Unwrap(); parent != nil {
+		return parent.Error()
+	}
+	return ""
+}
+
+// Format implements the `fmt.Formatter` interface so that SimpleErrors can be printed with different levels of detail.
+//
+//	%s, %v  the error string, as returned by Error()
+//	%q      the quoted error string
+//	%+v     the error string followed by every error in the tree (depth-first), with the code and its namespace and doc URL, flags,
+//	        field violations, auxiliary data and stack trace of each SimpleError
+//	%#v     a Go-syntax representation of the error, useful for debugging
+func (e *SimpleError) Format(s fmt.State, verb rune) {
+	if e == nil {
+		_, _ = io.WriteString(s, "<nil>")
+		return
+	}
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('#'):
+			e.formatGoSyntax(s)
+		case s.Flag('+'):
+			e.formatVerbose(s)
+		default:
+			_, _ = io.WriteString(s, e.Error())
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(*simplerr.SimpleError=%s)", verb, e.Error())
+	}
+}
+
+// formatVerbose writes the error string followed by a detailed breakdown of each error in the chain
+func (e *SimpleError) formatVerbose(w io.Writer) {
+	_, _ = io.WriteString(w, e.Error())
+
+	var n int
+	walk(e, func(err error) bool {
+		ii := n
+		n++
+
+		serr, ok := err.(*SimpleError)
+		if !ok {
+			_, _ = fmt.Fprintf(w, "\n[%d] %T: %s", ii, err, err.Error())
+			return false
+		}
+
+		_, _ = fmt.Fprintf(w, "\n[%d] %s", ii, serr.GetMessage())
+		if public, ok := serr.GetPublicMessage(); ok {
+			_, _ = fmt.Fprintf(w, "\n    public: %s", public)
+		}
+		if key, args, ok := serr.GetLocalized(); ok {
+			_, _ = fmt.Fprintf(w, "\n    localized: %s", key)
+			for _, k := range sortedKeys(args) {
+				_, _ = fmt.Fprintf(w, " %s=%v", k, args[k])
+			}
+		}
+		if name := GetRegistry().CodeName(serr.GetCode()); name != "" {
+			_, _ = fmt.Fprintf(w, "\n    code: %d %s (%s)", serr.GetCode(), name, serr.GetDescription())
+		} else {
+			_, _ = fmt.Fprintf(w, "\n    code: %d (%s)", serr.GetCode(), serr.GetDescription())
+		}
+		if namespace := GetRegistry().CodeNamespace(serr.GetCode()); namespace != "" {
+			_, _ = fmt.Fprintf(w, "\n    namespace: %s", namespace)
+		}
+		if docURL := GetRegistry().CodeDocURL(serr.GetCode()); docURL != "" {
+			_, _ = fmt.Fprintf(w, "\n    doc: %s", docURL)
+		}
+		if reason, benign := serr.GetBenignReason(); benign {
+			_, _ = fmt.Fprintf(w, "\n    benign: true (%s)", reason)
+		} else {
+			_, _ = io.WriteString(w, "\n    benign: false")
+		}
+		_, _ = fmt.Fprintf(w, "\n    silent: %t", serr.GetSilent())
+		if retryAfter, ok := serr.GetRetryAfter(); ok {
+			_, _ = fmt.Fprintf(w, "\n    retriable: true (retry after %s)", retryAfter)
+		} else {
+			_, _ = fmt.Fprintf(w, "\n    retriable: %t", serr.GetRetriable())
+		}
+
+		if severity := serr.GetSeverity(); severity != SeverityUnset {
+			_, _ = fmt.Fprintf(w, "\n    severity: %s", severity)
+		}
+
+		for _, v := range serr.GetFieldViolations() {
+			_, _ = fmt.Fprintf(w, "\n    field violation: %s (%s)", v.Field, v.Description)
+		}
+
+		if aux := serr.GetAuxiliary(); len(aux) > 0 {
+			_, _ = io.WriteString(w, "\n    aux:")
+			for _, k := range sortedKeys(aux) {
+				_, _ = fmt.Fprintf(w, " %s=%v", k, aux[k])
+			}
+		}
+
+		if stack := serr.StackTrace(); len(stack) > 0 {
+			_, _ = io.WriteString(w, "\n    stack:")
+			for _, call := range stack {
+				_, _ = fmt.Fprintf(w, "\n        %s\n            %s:%d", call.Func, call.File, call.Line)
+			}
+		}
+		return false
+	})
+}
+
+// formatGoSyntax writes a Go-syntax representation of the error
+func (e *SimpleError) formatGoSyntax(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "&simplerr.SimpleError{msg:%q, code:%d, benign:%t, benignReason:%q, silent:%t, retriable:%t, auxiliary:%#v, parent:%#v}",
+		e.msg, e.code, e.benign, e.benignReason, e.silent, e.retriable, e.auxiliary, e.parent)
+}
@@ -0,0 +1,103 @@
+package simplerr
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (s *TestSuite) TestFormatters() {
+	original := fmt.Errorf("original")
+	serr1 := Wrapf(original, "wrapper %d", 1)
+	serr2 := Wrapf(serr1, "wrapper %d", 2)
+	noMessage := Wrap(serr2)
+
+	s.Run("default formatter", func() {
+		s.Equal("wrapper 2: wrapper 1: original", serr2.Error())
+		s.Equal("wrapper 2: wrapper 1: original", noMessage.Error(), "empty messages do not add a separator")
+		s.Equal("", New("").Error())
+	})
+
+	s.Run("newline formatter", func() {
+		Formatter = NewlineFormatter
+		defer func() { Formatter = DefaultFormatter }()
+
+		s.Equal("wrapper 2\nwrapper 1\noriginal", serr2.Error())
+		s.Equal("wrapper 2\nwrapper 1\noriginal", noMessage.Error())
+	})
+
+	s.Run("message only formatter", func() {
+		Formatter = MessageOnlyFormatter
+		defer func() { Formatter = DefaultFormatter }()
+
+		s.Equal("wrapper 2", serr2.Error())
+		s.Equal("wrapper 2", noMessage.Error(), "empty messages render the wrapped error")
+		s.Equal("something", New("something").Error())
+		s.Equal("", New("").Error())
+	})
+
+	s.Run("custom formatter", func() {
+		Formatter = func(e *SimpleError) string {
+			return fmt.Sprintf("[%d] %s", e.GetCode(), e.GetMessage())
+		}
+		defer func() { Formatter = DefaultFormatter }()
+
+		s.Equal("[2] not here", New("not here").Code(CodeNotFound).Error())
+	})
+
+	s.Run("registry formatter takes precedence", func() {
+		r := NewRegistry()
+		r.SetFormatter(SeparatorFormatter(" <- "))
+		defaultRegistry := GetRegistry()
+		SetRegistry(r)
+		defer SetRegistry(defaultRegistry)
+
+		s.Equal("wrapper 2 <- wrapper 1 <- original", serr2.Error())
+
+		r.SetFormatter(nil)
+		s.Equal("wrapper 2: wrapper 1: original", serr2.Error(), "nil registry formatter uses the package formatter")
+	})
+}
+
+func (s *TestSuite) TestFormatVerbs() {
+	original := fmt.Errorf("original")
+	serr1 := Wrapf(original, "wrapper 1").Code(CodeNotFound).BenignReason("expected").Aux("b", 2, "a", 1)
+	serr2 := Wrapf(serr1, "wrapper 2").Retriable().Silence()
+
+	s.Run("plain verbs use the error string", func() {
+		s.Equal(serr2.Error(), fmt.Sprintf("%v", serr2))
+		s.Equal(serr2.Error(), fmt.Sprintf("%s", serr2))
+		s.Equal(`"wrapper 2: wrapper 1: original"`, fmt.Sprintf("%q", serr2))
+		s.Equal("%!d(*simplerr.SimpleError=wrapper 2: wrapper 1: original)", fmt.Sprintf("%d", serr2))
+	})
+
+	s.Run("verbose verb prints the chain", func() {
+		got := fmt.Sprintf("%+v", serr2)
+		lines := strings.Split(got, "\n")
+		s.Equal("wrapper 2: wrapper 1: original", lines[0])
+		s.Equal("[0] wrapper 2", lines[1])
+		s.Equal("    code: 0 UNKNOWN (unknown)", lines[2])
+		s.Equal("    benign: false", lines[3])
+		s.Equal("    silent: true", lines[4])
+		s.Equal("    retriable: true", lines[5])
+		s.Equal("    stack:", lines[6])
+		s.Contains(lines[7], "TestFormatVerbs")
+
+		s.Contains(got, "[1] wrapper 1\n    code: 2 NOT_FOUND (not found)\n    benign: true (expected)\n    silent: false\n    retriable: false\n    aux: a=1 b=2\n    stack:")
+		s.True(strings.HasSuffix(got, "[2] *errors.errorString: original"))
+	})
+
+	s.Run("verbose verb prints unnamed codes by number", func() {
+		got := fmt.Sprintf("%+v", New("unregistered").Code(1000))
+		s.Contains(got, "[0] unregistered\n    code: 1000 ()\n")
+	})
+
+	s.Run("verbose verb prints the severity when set", func() {
+		got := fmt.Sprintf("%+v", New("severe").Severity(SeverityCritical))
+		s.Contains(got, "    retriable: false\n    severity: CRITICAL\n")
+	})
+
+	s.Run("go syntax verb", func() {
+		got := fmt.Sprintf("%#v", serr1)
+		s.Equal(`&simplerr.SimpleError{msg:"wrapper 1", code:2, benign:true, benignReason:"expected", silent:false, retriable:false, auxiliary:map[string]interface {}{"a":1, "b":2}, parent:&errors.errorString{s:"original"}}`, got)
+	})
+}
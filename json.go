@@ -0,0 +1,205 @@
+package simplerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// jsonError is the JSON representation of an error in the chain
+type jsonError struct {
+	Message         string                 `json:"message"`
+	PublicMessage   string                 `json:"public_message,omitempty"`
+	MessageKey      string                 `json:"message_key,omitempty"`
+	MessageArgs     map[string]interface{} `json:"message_args,omitempty"`
+	Code            *int                   `json:"code,omitempty"`
+	CodeName        string                 `json:"code_name,omitempty"`
+	CodeDescription string                 `json:"code_description,omitempty"`
+	CodeNamespace   string                 `json:"code_namespace,omitempty"`
+	CodeDocURL      string                 `json:"code_doc_url,omitempty"`
+	Benign          bool                   `json:"benign,omitempty"`
+	BenignReason    string                 `json:"benign_reason,omitempty"`
+	Silent          bool                   `json:"silent,omitempty"`
+	Retriable       bool                   `json:"retriable,omitempty"`
+	RetryAfter      time.Duration          `json:"retry_after,omitempty"`
+	Severity        Severity               `json:"severity,omitempty"`
+	FieldViolations []FieldViolation       `json:"field_violations,omitempty"`
+	Auxiliary       map[string]interface{} `json:"auxiliary,omitempty"`
+	StackTrace      []Call                 `json:"stack_trace,omitempty"`
+	Wrapped         *jsonError             `json:"wrapped,omitempty"`
+	Joined          []*jsonError           `json:"joined,omitempty"`
+}
+
+// plainError is used to represent errors in a decoded chain that were not SimpleErrors when they were encoded.
+// It preserves the error string of the original error and the rest of the chain it wrapped.
+type plainError struct {
+	msg    string
+	parent error
+}
+
+// Error returns the error string of the original error
+func (e *plainError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the next error in the decoded chain
+func (e *plainError) Unwrap() error {
+	return e.parent
+}
+
+// plainJoinError is used to represent errors in a decoded chain that wrapped multiple errors (eg. `errors.Join()`)
+// when they were encoded.
+type plainJoinError struct {
+	msg  string
+	errs []error
+}
+
+// Error returns the error string of the original error
+func (e *plainJoinError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the errors that were wrapped by the original error
+func (e *plainJoinError) Unwrap() []error {
+	return e.errs
+}
+
+// MarshalJSON implements the `json.Marshaler` interface. The entire error chain is encoded, including the code
+// (both its number and name) with its namespace and doc URL, flags, auxiliary data and stack trace of each SimpleError. Errors in the chain that are not SimpleErrors are
+// encoded by their error string only. Attributes and attached loggers are not encoded. Auxiliary values and message
+// arguments that cannot be encoded (eg. channels, functions or NaN) are encoded as strings with `fmt.Sprint()` so that
+// they do not prevent the rest of the error from being encoded.
+func (e *SimpleError) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(toJSONError(e))
+}
+
+// UnmarshalJSON implements the `json.Unmarshaler` interface. It rebuilds the error chain encoded by MarshalJSON.
+// Errors in the chain that were not SimpleErrors are rebuilt as plain errors which preserve their error string.
+// Codes are decoded by their name when it is registered in the current registry and by their number otherwise.
+// Note that auxiliary values are decoded with the default JSON types (eg. numbers become float64).
+func (e *SimpleError) UnmarshalJSON(data []byte) error {
+	var je jsonError
+	if err := json.Unmarshal(data, &je); err != nil {
+		return err
+	}
+	*e = *simpleErrorFromJSON(&je)
+	return nil
+}
+
+// toJSONError converts an error tree to its JSON representation
+func toJSONError(err error) *jsonError {
+	if err == nil {
+		return nil
+	}
+
+	serr, ok := err.(*SimpleError)
+	if !ok {
+		je := &jsonError{Message: err.Error()}
+		if joinErr, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, wrapped := range joinErr.Unwrap() {
+				je.Joined = append(je.Joined, toJSONError(wrapped))
+			}
+			return je
+		}
+		je.Wrapped = toJSONError(errors.Unwrap(err))
+		return je
+	}
+
+	code := int(serr.GetCode())
+	reason, benign := serr.GetBenignReason()
+	retryAfter, _ := serr.GetRetryAfter()
+	return &jsonError{
+		Message:         serr.GetMessage(),
+		PublicMessage:   serr.publicMsg,
+		MessageKey:      serr.msgKey,
+		MessageArgs:     encodableValues(serr.msgArgs),
+		Code:            &code,
+		CodeName:        GetRegistry().CodeName(serr.GetCode()),
+		CodeDescription: serr.GetDescription(),
+		CodeNamespace:   GetRegistry().CodeNamespace(serr.GetCode()),
+		CodeDocURL:      GetRegistry().CodeDocURL(serr.GetCode()),
+		Benign:          benign,
+		BenignReason:    reason,
+		Silent:          serr.GetSilent(),
+		Retriable:       serr.GetRetriable(),
+		RetryAfter:      retryAfter,
+		Severity:        serr.GetSeverity(),
+		FieldViolations: serr.violations,
+		Auxiliary:       encodableValues(serr.GetAuxiliary()),
+		StackTrace:      serr.StackTrace(),
+		Wrapped:         toJSONError(serr.Unwrap()),
+	}
+}
+
+// encodableValues returns a copy of the values in which each value is encoded to JSON on its own. Values that cannot
+// be encoded are replaced by their string representation.
+func encodableValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	encodable := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			encodable[k] = fmt.Sprint(v)
+			continue
+		}
+		encodable[k] = json.RawMessage(data)
+	}
+	return encodable
+}
+
+// fromJSONError rebuilds an error chain from its JSON representation
+func fromJSONError(je *jsonError) error {
+	// Only SimpleErrors are encoded with a code
+	if je.Code == nil {
+		if je.Joined != nil {
+			errs := make([]error, 0, len(je.Joined))
+			for _, joined := range je.Joined {
+				errs = append(errs, fromJSONError(joined))
+			}
+			return &plainJoinError{msg: je.Message, errs: errs}
+		}
+		return &plainError{msg: je.Message, parent: parentFromJSON(je)}
+	}
+	return simpleErrorFromJSON(je)
+}
+
+// simpleErrorFromJSON rebuilds a SimpleError and the chain it wraps from its JSON representation
+func simpleErrorFromJSON(je *jsonError) *SimpleError {
+	serr := &SimpleError{
+		parent:       parentFromJSON(je),
+		msg:          je.Message,
+		publicMsg:    je.PublicMessage,
+		msgKey:       je.MessageKey,
+		msgArgs:      je.MessageArgs,
+		benign:       je.Benign,
+		benignReason: je.BenignReason,
+		silent:       je.Silent,
+		retriable:    je.Retriable,
+		retryAfter:   je.RetryAfter,
+		severity:     je.Severity,
+		violations:   je.FieldViolations,
+		auxiliary:    je.Auxiliary,
+		stackTrace:   je.StackTrace,
+	}
+	// Prefer the name of the code, which is stable across services, over its number
+	if code, ok := GetRegistry().Lookup(je.CodeName); ok {
+		serr.code = code
+	} else if je.Code != nil {
+		serr.code = Code(*je.Code)
+	}
+	return serr
+}
+
+// parentFromJSON rebuilds the wrapped error chain, if any
+func parentFromJSON(je *jsonError) error {
+	if je.Wrapped == nil {
+		return nil
+	}
+	return fromJSONError(je.Wrapped)
+}
@@ -0,0 +1,122 @@
+package simplerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+func (s *TestSuite) TestJSONRoundTrip() {
+	original := fmt.Errorf("original")
+	serr1 := Wrapf(original, "wrapper 1").Code(CodeNotFound).BenignReason("expected").Aux("id", 123)
+	stdlibWrapped := fmt.Errorf("stdlib wrapper: %w", serr1)
+	serr2 := Wrapf(stdlibWrapped, "wrapper 2").Retriable().Silence().Aux("name", "calvin")
+
+	data, err := json.Marshal(serr2)
+	s.Require().NoError(err)
+
+	got := &SimpleError{}
+	s.Require().NoError(json.Unmarshal(data, got))
+
+	s.Equal(serr2.Error(), got.Error())
+	s.Equal(serr2.GetMessage(), got.GetMessage())
+	s.True(HasErrorCode(got, CodeNotFound))
+	s.True(IsRetriable(got))
+	s.True(IsSilent(got))
+	reason, benign := IsBenign(got)
+	s.True(benign)
+	s.Equal("expected", reason)
+	s.Equal(map[string]interface{}{"id": float64(123), "name": "calvin"}, ExtractAuxiliary(got))
+	s.Equal(serr2.StackTrace(), got.StackTrace())
+	s.Nil(got.StackFrames())
+
+	// The standard library wrapper is rebuilt as a plain error which keeps its message and the rest of the chain
+	plain := got.Unwrap()
+	s.Equal(stdlibWrapped.Error(), plain.Error())
+	inner := As(plain)
+	s.Require().NotNil(inner)
+	s.Equal(serr1.StackTrace(), inner.StackTrace())
+	s.Equal("original", inner.Unwrap().Error())
+	s.Nil(errors.Unwrap(inner.Unwrap()))
+
+	s.Run("encoded fields", func() {
+		raw := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(data, &raw))
+		s.Equal("wrapper 2", raw["message"])
+		s.Equal(float64(CodeUnknown), raw["code"])
+		s.Equal("unknown", raw["code_description"])
+		s.Equal(true, raw["retriable"])
+		s.Equal(true, raw["silent"])
+		s.NotContains(raw, "benign")
+		s.NotEmpty(raw["stack_trace"])
+
+		wrapped := raw["wrapped"].(map[string]interface{}) // nolint: errcheck
+		s.Equal(stdlibWrapped.Error(), wrapped["message"])
+		s.NotContains(wrapped, "code", "non-SimpleErrors are encoded by their message only")
+	})
+
+	s.Run("decode a SimpleError without a code", func() {
+		got := &SimpleError{}
+		s.Require().NoError(json.Unmarshal([]byte(`{"message": "no code"}`), got))
+		s.Equal("no code", got.Error())
+		s.Equal(CodeUnknown, got.GetCode())
+	})
+
+	s.Run("codes are encoded by number and name", func() {
+		raw := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(data, &raw))
+		wrapped := raw["wrapped"].(map[string]interface{})["wrapped"].(map[string]interface{}) // nolint: errcheck
+		s.Equal(float64(CodeNotFound), wrapped["code"])
+		s.Equal("NOT_FOUND", wrapped["code_name"])
+	})
+
+	s.Run("decode prefers the code name", func() {
+		got := &SimpleError{}
+		s.Require().NoError(json.Unmarshal([]byte(`{"message": "renumbered", "code": 99, "code_name": "NOT_FOUND"}`), got))
+		s.Equal(CodeNotFound, got.GetCode())
+
+		s.Require().NoError(json.Unmarshal([]byte(`{"message": "unknown name", "code": 99, "code_name": "NOT_A_CODE"}`), got))
+		s.Equal(Code(99), got.GetCode(), "unknown names fall back to the number")
+	})
+
+	s.Run("severity round trip", func() {
+		data, err := json.Marshal(New("severe").Severity(SeverityWarn))
+		s.Require().NoError(err)
+		got := &SimpleError{}
+		s.Require().NoError(json.Unmarshal(data, got))
+		s.Equal(SeverityWarn, got.GetSeverity())
+	})
+
+	s.Run("decode invalid JSON", func() {
+		got := &SimpleError{}
+		s.Error(json.Unmarshal([]byte(`{"message": 1}`), got))
+	})
+}
+
+func (s *TestSuite) TestJSONUnsupportedValues() {
+	type cycle struct {
+		Next *cycle
+	}
+	loop := &cycle{}
+	loop.Next = loop
+
+	ch := make(chan int)
+	serr := New("something").
+		Localized("greeting", "fn", func() {}).
+		Aux("ch", ch, "nan", math.NaN(), "loop", loop, "id", 1)
+	data, err := json.Marshal(Wrapf(serr, "wrapped"))
+	s.Require().NoError(err, "values that cannot be encoded do not fail the whole error")
+
+	got := &SimpleError{}
+	s.Require().NoError(json.Unmarshal(data, got))
+	aux := got.Unwrap().(*SimpleError).GetAuxiliary() // nolint: errcheck
+	s.Equal(fmt.Sprint(ch), aux["ch"])
+	s.Equal("NaN", aux["nan"])
+	s.Equal(fmt.Sprint(loop), aux["loop"])
+	s.Equal(float64(1), aux["id"], "values that can be encoded are unchanged")
+
+	_, args, ok := got.Unwrap().(*SimpleError).GetLocalized() // nolint: errcheck
+	s.True(ok)
+	s.IsType("", args["fn"])
+}
@@ -0,0 +1,188 @@
+package simplerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Catalog is a source of translated, client-facing messages. Messages are identified by a key and may contain
+// `{name}` placeholders which are replaced by the arguments given to `SimpleError.Localized()`.
+type Catalog interface {
+	// Lookup returns the message template for the key in the first of the languages that has a translation
+	Lookup(key string, langs ...string) (string, bool)
+}
+
+// MessageCatalog is a Catalog which stores messages in memory, keyed by language tag (eg. "en", "en-US", "fr").
+// It is safe for concurrent use.
+type MessageCatalog struct {
+	mu          sync.RWMutex
+	defaultLang string
+	// messages maps a lower cased language tag to the message templates keyed by message key
+	messages map[string]map[string]string
+}
+
+// NewMessageCatalog creates an empty catalog. The default language is used when none of the requested languages have
+// a translation. An empty default language disables the fallback.
+func NewMessageCatalog(defaultLang string) *MessageCatalog {
+	return &MessageCatalog{defaultLang: defaultLang, messages: map[string]map[string]string{}}
+}
+
+// LoadMessageCatalog creates a catalog from the JSON files in the root of the file system, which is typically an
+// `embed.FS`. Each file is named after its language tag (eg. "en.json", "fr-CA.json") and contains an object that maps
+// message keys to message templates:
+//
+//	{"order.not_found": "Order {id} was not found"}
+func LoadMessageCatalog(fsys fs.FS, defaultLang string) (*MessageCatalog, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, Wrapf(err, "failed to list message catalog files")
+	}
+
+	c := NewMessageCatalog(defaultLang)
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, Wrapf(err, "failed to read message catalog file %s", file)
+		}
+		var messages map[string]string
+		if err = json.Unmarshal(data, &messages); err != nil {
+			return nil, Wrapf(err, "failed to decode message catalog file %s", file)
+		}
+		c.Add(strings.TrimSuffix(file, path.Ext(file)), messages)
+	}
+	return c, nil
+}
+
+// Add adds the messages for the language to the catalog, replacing any existing messages with the same keys.
+// Messages can be decoded from any format, such as YAML, and added with Add.
+func (c *MessageCatalog) Add(lang string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lang = strings.ToLower(lang)
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string, len(messages))
+	}
+	for k, v := range messages {
+		c.messages[lang][k] = v
+	}
+}
+
+// Lookup returns the message template for the key in the first of the languages that has a translation.
+// Language tags are matched case-insensitively and regional tags fall back to their base language
+// (eg. "en-US" falls back to "en"). If no language has a translation, the default language is used.
+func (c *MessageCatalog) Lookup(key string, langs ...string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, lang := range langs {
+		if msg, ok := c.lookup(key, lang); ok {
+			return msg, true
+		}
+	}
+	return c.lookup(key, c.defaultLang)
+}
+
+// lookup returns the message template for the key in the language, falling back to its base language
+func (c *MessageCatalog) lookup(key, lang string) (string, bool) {
+	lang = strings.ToLower(lang)
+	if msg, ok := c.messages[lang][key]; ok {
+		return msg, true
+	}
+	if base, _, regional := strings.Cut(lang, "-"); regional {
+		if msg, ok := c.messages[base][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Translate returns the client-facing message of the error in the first of the languages that has a translation in
+// the catalog of the current registry (see `Registry.SetCatalog()`). The localized error nearest to the top of the
+// chain is used (see `SimpleError.Localized()`). If the error cannot be translated, the public message of the error
+// or the description of its code is returned along with false (see `GetPublicMessage()`).
+func Translate(err error, langs ...string) (string, bool) {
+	type LocalizedError interface {
+		GetLocalized() (string, map[string]interface{}, bool)
+	}
+
+	var key string
+	var args map[string]interface{}
+	found := walk(err, func(e error) bool {
+		localizedErr, ok := e.(LocalizedError)
+		if !ok {
+			return false
+		}
+		key, args, ok = localizedErr.GetLocalized()
+		return ok
+	})
+
+	if catalog := GetRegistry().catalog(); found && catalog != nil {
+		if msg, ok := catalog.Lookup(key, langs...); ok {
+			return expandMessage(msg, args), true
+		}
+	}
+	msg, _ := GetPublicMessage(err)
+	return msg, false
+}
+
+// expandMessage replaces the `{name}` placeholders in the message template with the arguments
+func expandMessage(msg string, args map[string]interface{}) string {
+	if len(args) == 0 {
+		return msg
+	}
+	oldnew := make([]string, 0, 2*len(args))
+	for _, k := range sortedKeys(args) {
+		oldnew = append(oldnew, "{"+k+"}", fmt.Sprint(args[k]))
+	}
+	return strings.NewReplacer(oldnew...).Replace(msg)
+}
+
+// AcceptedLanguages parses the value of an Accept-Language header (eg. "fr-CA,fr;q=0.9,en;q=0.8") and returns the
+// language tags in order of preference. Wildcards and languages with a quality of zero are omitted.
+func AcceptedLanguages(header string) []string {
+	type accepted struct {
+		lang    string
+		quality float64
+	}
+
+	var langs []accepted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+		lang = strings.TrimSpace(lang)
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if lang == "" || lang == "*" || quality <= 0 {
+			continue
+		}
+		langs = append(langs, accepted{lang: lang, quality: quality})
+	}
+
+	slices.SortStableFunc(langs, func(a, b accepted) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	tags := make([]string, 0, len(langs))
+	for _, l := range langs {
+		tags = append(tags, l.lang)
+	}
+	return tags
+}
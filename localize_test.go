@@ -0,0 +1,226 @@
+package simplerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"testing/fstest"
+)
+
+// globErrorFS is a file system whose Glob always fails
+type globErrorFS struct {
+	fstest.MapFS
+}
+
+func (globErrorFS) Glob(string) ([]string, error) {
+	return nil, fmt.Errorf("glob failed")
+}
+
+func (s *TestSuite) TestMessageCatalog() {
+	c := NewMessageCatalog("en")
+	c.Add("en", map[string]string{"order.not_found": "Order {id} was not found", "greeting": "Hello"})
+	c.Add("fr", map[string]string{"order.not_found": "La commande {id} est introuvable"})
+	c.Add("fr-CA", map[string]string{"greeting": "Allo"})
+
+	s.Run("lookup by preference", func() {
+		msg, ok := c.Lookup("order.not_found", "de", "fr", "en")
+		s.True(ok)
+		s.Equal("La commande {id} est introuvable", msg)
+	})
+
+	s.Run("regional tags fall back to the base language", func() {
+		msg, ok := c.Lookup("order.not_found", "FR-ca")
+		s.True(ok)
+		s.Equal("La commande {id} est introuvable", msg)
+
+		msg, ok = c.Lookup("greeting", "fr-CA")
+		s.True(ok)
+		s.Equal("Allo", msg, "regional translations take precedence")
+	})
+
+	s.Run("default language", func() {
+		msg, ok := c.Lookup("greeting", "de")
+		s.True(ok)
+		s.Equal("Hello", msg)
+	})
+
+	s.Run("missing key", func() {
+		_, ok := c.Lookup("missing", "en")
+		s.False(ok)
+	})
+
+	s.Run("languages are not modified", func() {
+		langs := make([]string, 1, 2)
+		langs[0] = "de"
+		spare := langs[:2]
+		spare[1] = "fr"
+
+		msg, _ := c.Lookup("order.not_found", langs...)
+		s.Equal("Order {id} was not found", msg)
+		s.Equal([]string{"de", "fr"}, spare, "the spare capacity of the languages is not written to")
+	})
+
+	s.Run("add replaces existing messages", func() {
+		c.Add("EN", map[string]string{"greeting": "Hi"})
+		msg, _ := c.Lookup("greeting")
+		s.Equal("Hi", msg)
+		msg, _ = c.Lookup("order.not_found")
+		s.Equal("Order {id} was not found", msg, "other messages are kept")
+	})
+}
+
+func (s *TestSuite) TestLoadMessageCatalog() {
+	s.Run("load json files", func() {
+		fsys := fstest.MapFS{
+			"en.json":    {Data: []byte(`{"order.not_found": "Order {id} was not found"}`)},
+			"fr-CA.json": {Data: []byte(`{"order.not_found": "La commande {id} est introuvable"}`)},
+			"README.md":  {Data: []byte(`ignored`)},
+		}
+		c, err := LoadMessageCatalog(fsys, "en")
+		s.NoError(err)
+
+		msg, ok := c.Lookup("order.not_found", "fr-ca")
+		s.True(ok)
+		s.Equal("La commande {id} est introuvable", msg)
+
+		msg, ok = c.Lookup("order.not_found", "de")
+		s.True(ok)
+		s.Equal("Order {id} was not found", msg)
+	})
+
+	s.Run("invalid json", func() {
+		_, err := LoadMessageCatalog(fstest.MapFS{"en.json": {Data: []byte(`[]`)}}, "en")
+		var syntaxErr *json.UnmarshalTypeError
+		s.ErrorAs(err, &syntaxErr)
+		s.ErrorContains(err, "failed to decode message catalog file en.json")
+	})
+
+	s.Run("unreadable file", func() {
+		_, err := LoadMessageCatalog(fstest.MapFS{"en.json": {Mode: fs.ModeDir}}, "en")
+		s.ErrorContains(err, "failed to read message catalog file en.json")
+	})
+
+	s.Run("glob error", func() {
+		_, err := LoadMessageCatalog(globErrorFS{}, "en")
+		s.EqualError(err, "failed to list message catalog files: glob failed")
+	})
+}
+
+func (s *TestSuite) TestTranslate() {
+	c := NewMessageCatalog("en")
+	c.Add("en", map[string]string{
+		"order.not_found": "Order {id} was not found",
+		"order.too_large": "Order {id} has {count} items, the limit is {limit}",
+	})
+	c.Add("fr", map[string]string{"order.not_found": "La commande {id} est introuvable"})
+
+	r := GetRegistry()
+	r.SetCatalog(c)
+	defer r.SetCatalog(nil)
+
+	s.Run("localized error", func() {
+		err := New("order 42 not found").Code(CodeNotFound).Localized("order.not_found", "id", 42)
+		key, args, ok := err.GetLocalized()
+		s.True(ok)
+		s.Equal("order.not_found", key)
+		s.Equal(map[string]interface{}{"id": 42}, args)
+
+		msg, ok := Translate(err, "fr")
+		s.True(ok)
+		s.Equal("La commande 42 est introuvable", msg)
+
+		msg, ok = Translate(err)
+		s.True(ok)
+		s.Equal("Order 42 was not found", msg)
+	})
+
+	s.Run("arguments are optional", func() {
+		c.Add("en", map[string]string{"order.failed": "Order failed"})
+		msg, ok := Translate(New("order failed").Localized("order.failed"))
+		s.True(ok)
+		s.Equal("Order failed", msg)
+	})
+
+	s.Run("multiple arguments", func() {
+		err := New("too large").Localized("order.too_large", "id", 42, "count", 120, "limit", 100, 5, "non-string key", "odd")
+		msg, ok := Translate(err)
+		s.True(ok)
+		s.Equal("Order 42 has 120 items, the limit is 100", msg)
+	})
+
+	s.Run("nearest localized error is used", func() {
+		inner := New("order 42 not found").Localized("order.not_found", "id", 42)
+		err := Wrapf(Wrapf(inner, "no key here"), "too large").Localized("order.too_large", "id", 1, "count", 2, "limit", 3)
+		msg, _ := Translate(err)
+		s.Equal("Order 1 has 2 items, the limit is 3", msg)
+
+		msg, _ = Translate(fmt.Errorf("wrapped: %w", inner))
+		s.Equal("Order 42 was not found", msg)
+	})
+
+	s.Run("missing translation falls back to the public message", func() {
+		err := New("no translation").Code(CodeNotFound).Public("not here").Localized("missing")
+		msg, ok := Translate(err, "fr")
+		s.False(ok)
+		s.Equal("not here", msg)
+
+		msg, ok = Translate(New("no translation").Code(CodeNotFound).Localized("missing"))
+		s.False(ok)
+		s.Equal("not found", msg, "falls back to the code description")
+	})
+
+	s.Run("no catalog", func() {
+		r.SetCatalog(nil)
+		defer r.SetCatalog(c)
+
+		msg, ok := Translate(New("order 42 not found").Code(CodeNotFound).Localized("order.not_found", "id", 42))
+		s.False(ok)
+		s.Equal("not found", msg)
+	})
+
+	s.Run("not localized", func() {
+		msg, ok := Translate(fmt.Errorf("secret"))
+		s.False(ok)
+		s.Equal("unknown", msg)
+	})
+
+	s.Run("clone keeps the message", func() {
+		err := New("order 42 not found").Localized("order.not_found", "id", 42)
+		clone := err.Clone().Localized("order.not_found", "id", 7)
+		msg, _ := Translate(err)
+		s.Equal("Order 42 was not found", msg)
+		msg, _ = Translate(clone)
+		s.Equal("Order 7 was not found", msg)
+	})
+
+	s.Run("encoding", func() {
+		err := New("order 42 not found").Localized("order.not_found", "id", 42)
+		s.Contains(fmt.Sprintf("%+v", err), "[0] order 42 not found\n    localized: order.not_found id=42\n")
+
+		data, jsonErr := json.Marshal(err)
+		s.NoError(jsonErr)
+		s.Contains(string(data), `"message_key":"order.not_found","message_args":{"id":42}`)
+
+		var decoded SimpleError
+		s.NoError(json.Unmarshal(data, &decoded))
+		msg, ok := Translate(&decoded, "fr")
+		s.True(ok)
+		s.Equal("La commande 42 est introuvable", msg)
+	})
+}
+
+func (s *TestSuite) TestAcceptedLanguages() {
+	testCases := []struct {
+		header   string
+		expected []string
+	}{
+		{"", []string{}},
+		{"fr", []string{"fr"}},
+		{"fr-CA,fr;q=0.9,en;q=0.8", []string{"fr-CA", "fr", "en"}},
+		{"en;q=0.5, de, fr;q=0.5", []string{"de", "en", "fr"}},
+		{"*, en;q=0.1, fr;q=0, de;q=bad", []string{"en"}},
+	}
+	for _, tc := range testCases {
+		s.Equal(tc.expected, AcceptedLanguages(tc.header), tc.header)
+	}
+}
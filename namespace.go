@@ -0,0 +1,149 @@
+package simplerr
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Namespace is a range of error codes reserved by a module in a registry. Modules that share a registry register their
+// codes within their own namespace so that collisions are detected when the namespaces are reserved rather than when
+// a conflicting code happens to be registered.
+//
+//	var billing = simplerr.GetRegistry().Namespace("billing", 1000, 1999)
+//
+//	const CodeCardDeclined = 1000
+//
+//	func init() {
+//		billing.RegisterCode(CodeCardDeclined, simplerr.CodeMetadata{Name: "CARD_DECLINED", Description: "card declined"})
+//	}
+type Namespace struct {
+	registry *Registry
+	name     string
+	min, max Code
+}
+
+// Namespace reserves the range of error codes from min to max (inclusive) under the given name. Codes in the range
+// can only be registered with the returned Namespace.
+// This call will panic if the name is invalid or already in use, if the range is invalid or overlaps the range of
+// another namespace, or if codes in the range have already been registered outside the namespace.
+// Namespace names consist of letters, digits and underscores and must start with a letter.
+// This method should be called early on application startup.
+func (r *Registry) Namespace(name string, min, max Code) *Namespace {
+	ns, err := r.TryNamespace(name, min, max)
+	if err != nil {
+		panic(err)
+	}
+	return ns
+}
+
+// TryNamespace is like Namespace but returns an error rather than panicking
+func (r *Registry) TryNamespace(name string, min, max Code) (*Namespace, error) {
+	ns := &Namespace{registry: r, name: name, min: min, max: max}
+	err := r.update(func(st *registryState) error {
+		if st.frozen {
+			return Wrapf(ErrRegistryFrozen.New(), "cannot register namespace %q", name)
+		}
+		if !validCodeName.MatchString(strings.ToUpper(name)) {
+			return Wrapf(ErrInvalidName.New(), "invalid namespace name %q, names must match %s when upper cased", name, validCodeName)
+		}
+		if min > max {
+			return Wrapf(ErrCodeOutOfRange.New(), "invalid range for namespace %q, %d is greater than %d", name, min, max)
+		}
+		if min < NumberOfReservedCodes {
+			return Wrapf(ErrCodeOutOfRange.New(), "invalid range for namespace %q, SimpleError codes 0 to %d are reserved",
+				name, NumberOfReservedCodes-1)
+		}
+
+		for _, other := range st.namespaces {
+			if other.name == name {
+				return Wrapf(ErrCodeConflict.New(), "namespace %q already registered with range %d to %d",
+					name, other.min, other.max)
+			}
+			if min <= other.max && other.min <= max {
+				return Wrapf(ErrCodeConflict.New(), "range %d to %d of namespace %q overlaps range %d to %d of namespace %q",
+					min, max, name, other.min, other.max, other.name)
+			}
+		}
+		for _, code := range slices.Sorted(maps.Keys(st.codes)) {
+			if code >= min && code <= max {
+				return Wrapf(ErrCodeConflict.New(), "range %d to %d of namespace %q contains error code %d which is already registered",
+					min, max, name, code)
+			}
+		}
+
+		st.namespaces = append(st.namespaces, ns)
+		slices.SortFunc(st.namespaces, func(a, b *Namespace) int {
+			return int(a.min - b.min)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// Namespaces returns the namespaces reserved in the registry, sorted by the start of their range
+func (r *Registry) Namespaces() []*Namespace {
+	return slices.Clone(r.load().namespaces)
+}
+
+// namespaceOf returns the namespace whose range contains the code, if any
+func (st *registryState) namespaceOf(code Code) *Namespace {
+	for _, ns := range st.namespaces {
+		if code >= ns.min && code <= ns.max {
+			return ns
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the namespace
+func (ns *Namespace) Name() string {
+	return ns.name
+}
+
+// Range returns the first and last error codes (inclusive) reserved by the namespace
+func (ns *Namespace) Range() (min, max Code) {
+	return ns.min, ns.max
+}
+
+// RegisterErrorCode registers a custom error code within the namespace. See `Namespace.RegisterCode()`.
+func (ns *Namespace) RegisterErrorCode(code Code, description string) {
+	ns.RegisterCode(code, CodeMetadata{Description: description})
+}
+
+// TryRegisterErrorCode is like RegisterErrorCode but returns an error rather than panicking
+func (ns *Namespace) TryRegisterErrorCode(code Code, description string) error {
+	return ns.TryRegisterCode(code, CodeMetadata{Description: description})
+}
+
+// RegisterCode registers a custom error code within the namespace along with its metadata. The name of the code is
+// prefixed with the upper cased namespace name (eg. CARD_DECLINED becomes BILLING_CARD_DECLINED) unless it already
+// has the prefix. This call will panic if the code is outside the range of the namespace or, like
+// `Registry.RegisterCode()`, if the code or name is already registered or the name is invalid.
+func (ns *Namespace) RegisterCode(code Code, meta CodeMetadata) {
+	if err := ns.TryRegisterCode(code, meta); err != nil {
+		panic(err)
+	}
+}
+
+// TryRegisterCode is like RegisterCode but returns an error rather than panicking
+func (ns *Namespace) TryRegisterCode(code Code, meta CodeMetadata) error {
+	return ns.registry.update(func(st *registryState) error {
+		if st.frozen {
+			return Wrapf(ErrRegistryFrozen.New(), "cannot register error code %d", code)
+		}
+		if code < ns.min || code > ns.max {
+			return Wrapf(ErrCodeOutOfRange.New(), "error code %d is outside the range %d to %d of namespace %q",
+				code, ns.min, ns.max, ns.name)
+		}
+
+		meta.Namespace = ns.name
+		if prefix := strings.ToUpper(ns.name) + "_"; meta.Name != "" && !strings.HasPrefix(meta.Name, prefix) {
+			meta.Name = prefix + meta.Name
+		}
+		return st.register(code, meta)
+	})
+}
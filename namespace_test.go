@@ -0,0 +1,158 @@
+package simplerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+func (s *TestSuite) TestNamespaces() {
+	r := NewRegistry()
+	billing := r.Namespace("billing", 1000, 1999)
+	shipping := r.Namespace("shipping", 3000, 3999)
+	r.RegisterErrorCode(100, "outside any namespace")
+	// Because the registry is a global, to prevent mucking with other tests, set it back afterwards
+	defaultRegistry := GetRegistry()
+	SetRegistry(r)
+	defer SetRegistry(defaultRegistry)
+
+	const (
+		CodeCardDeclined = 1000
+		CodeRefunded     = 1001
+		CodeLost         = 3000
+	)
+	billing.RegisterCode(CodeCardDeclined, CodeMetadata{Name: "CARD_DECLINED", Description: "card declined"})
+	billing.RegisterCode(CodeRefunded, CodeMetadata{Name: "BILLING_REFUNDED", Description: "refunded"})
+	shipping.RegisterErrorCode(CodeLost, "lost in transit")
+
+	s.Run("namespaces", func() {
+		s.Equal("billing", billing.Name())
+		from, to := billing.Range()
+		s.Equal(Code(1000), from)
+		s.Equal(Code(1999), to)
+
+		r.Namespace("accounts", 2000, 2999)
+		var names []string
+		for _, ns := range r.Namespaces() {
+			names = append(names, ns.Name())
+		}
+		s.Equal([]string{"billing", "accounts", "shipping"}, names, "namespaces are sorted by range")
+	})
+
+	s.Run("codes belong to their namespace", func() {
+		meta, ok := r.CodeMetadata(CodeCardDeclined)
+		s.True(ok)
+		s.Equal("billing", meta.Namespace)
+		s.Equal("billing", r.CodeNamespace(CodeCardDeclined))
+		s.Equal("shipping", r.CodeNamespace(CodeLost))
+		s.Equal("", r.CodeNamespace(100))
+		s.Equal("billing", r.ErrorCodeMetadata()[CodeRefunded].Namespace)
+	})
+
+	s.Run("code names are prefixed with the namespace", func() {
+		s.Equal("BILLING_CARD_DECLINED", Code(CodeCardDeclined).String())
+		s.Equal("BILLING_REFUNDED", Code(CodeRefunded).String(), "names are not prefixed twice")
+		code, ok := r.Lookup("BILLING_CARD_DECLINED")
+		s.True(ok)
+		s.Equal(Code(CodeCardDeclined), code)
+	})
+
+	s.Run("namespace cannot be set directly", func() {
+		r.RegisterCode(101, CodeMetadata{Namespace: "billing"})
+		s.Equal("", r.CodeNamespace(101))
+	})
+
+	s.Run("invalid namespaces", func() {
+		testCases := []struct {
+			name     string
+			min, max Code
+			template *Template
+			msg      string
+		}{
+			{"billing", 5000, 5999, ErrCodeConflict, `namespace "billing" already registered with range 1000 to 1999`},
+			{"payments", 1500, 2500, ErrCodeConflict, `range 1500 to 2500 of namespace "payments" overlaps range 1000 to 1999 of namespace "billing"`},
+			{"payments", 500, 1000, ErrCodeConflict, `range 500 to 1000 of namespace "payments" overlaps range 1000 to 1999 of namespace "billing"`},
+			{"payments", 100, 199, ErrCodeConflict, `range 100 to 199 of namespace "payments" contains error code 100 which is already registered`},
+			{"payments", 50, 199, ErrCodeOutOfRange, `invalid range for namespace "payments", SimpleError codes 0 to 99 are reserved`},
+			{"payments", 5999, 5000, ErrCodeOutOfRange, `invalid range for namespace "payments", 5999 is greater than 5000`},
+			{"pay-ments", 5000, 5999, ErrInvalidName, `invalid namespace name "pay-ments", names must match ^[A-Z][A-Z0-9_]*$ when upper cased`},
+			{"", 5000, 5999, ErrInvalidName, `invalid namespace name "", names must match ^[A-Z][A-Z0-9_]*$ when upper cased`},
+		}
+		for _, tc := range testCases {
+			ns, err := r.TryNamespace(tc.name, tc.min, tc.max)
+			s.Nil(ns)
+			s.ErrorIs(err, tc.template)
+			s.EqualError(err, tc.msg+": "+tc.template.Error())
+			s.PanicsWithError(err.Error(), func() {
+				r.Namespace(tc.name, tc.min, tc.max)
+			})
+		}
+		s.Len(r.Namespaces(), 3, "invalid namespaces are not registered")
+	})
+
+	s.Run("invalid codes", func() {
+		testCases := []struct {
+			register func() error
+			template *Template
+			msg      string
+		}{
+			{
+				func() error { return r.TryRegisterErrorCode(1500, "sneaky") },
+				ErrCodeOutOfRange,
+				`error code 1500 is in the range of namespace "billing" (1000 to 1999) and must be registered with the namespace`,
+			},
+			{
+				func() error { return billing.TryRegisterErrorCode(CodeLost, "lost") },
+				ErrCodeOutOfRange,
+				`error code 3000 is outside the range 1000 to 1999 of namespace "billing"`,
+			},
+			{
+				func() error { return billing.TryRegisterErrorCode(CodeCardDeclined, "declined again") },
+				ErrCodeConflict,
+				`error code 1000 already registered in namespace "billing"`,
+			},
+			{
+				func() error { return billing.TryRegisterCode(1002, CodeMetadata{Name: "CARD_DECLINED"}) },
+				ErrCodeConflict,
+				`error code name "BILLING_CARD_DECLINED" already registered to code 1000`,
+			},
+			{
+				func() error { return r.TryRegisterErrorCode(100, "again") },
+				ErrCodeConflict,
+				`error code 100 already registered`,
+			},
+		}
+		for _, tc := range testCases {
+			err := tc.register()
+			s.ErrorIs(err, tc.template)
+			s.EqualError(err, tc.msg+": "+tc.template.Error())
+		}
+
+		s.Panics(func() {
+			r.RegisterErrorCode(1500, "sneaky")
+		})
+		s.Panics(func() {
+			billing.RegisterErrorCode(CodeLost, "lost")
+		})
+	})
+
+	s.Run("namespace is exposed", func() {
+		serr := New("declined").Code(CodeCardDeclined)
+
+		s.Contains(fmt.Sprintf("%+v", serr), "code: 1000 BILLING_CARD_DECLINED (card declined)\n    namespace: billing\n")
+
+		data, err := json.Marshal(serr)
+		s.Require().NoError(err)
+		raw := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(data, &raw))
+		s.Equal("billing", raw["code_namespace"])
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&output, nil))
+		logger.Info("failed", "err", serr)
+		logged := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(output.Bytes(), &logged))
+		s.Equal("billing", logged["err"].(map[string]interface{})["code_namespace"]) // nolint: errcheck
+	})
+}
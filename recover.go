@@ -0,0 +1,61 @@
+package simplerr
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// ErrPanic is the template of errors created from recovered panics. Use `errors.Is(err, simplerr.ErrPanic)` to detect them.
+var ErrPanic = Define(CodeInternal, "panic")
+
+// Recover recovers from a panic and sets the error pointed to by err to a SimpleError created from ErrPanic. The error
+// has CodeInternal, the panic value as the "panic" auxiliary data and the stack trace of the point at which the
+// panic occurred. If the panic value is an error, it is wrapped. It must be called directly with defer, and is
+// typically used with a named error return value:
+//
+//	func Handle() (err error) {
+//		defer simplerr.Recover(&err)
+//		...
+//	}
+//
+// Panics with `http.ErrAbortHandler`, which the net/http server uses to abort a response, are not recovered.
+func Recover(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if rErr, ok := r.(error); ok && errors.Is(rErr, http.ErrAbortHandler) {
+		panic(r)
+	}
+	*err = panicError(r)
+}
+
+// panicError creates an error from the recovered panic value
+func panicError(r interface{}) *SimpleError {
+	var serr *SimpleError
+	if rErr, ok := r.(error); ok {
+		serr = ErrPanic.Wrap(rErr)
+	} else {
+		serr = ErrPanic.New().Message("panic: %v", r)
+	}
+	serr.rawStackFrames = panicStackFrames()
+	serr.applyStackPolicy()
+	return serr.Aux("panic", r)
+}
+
+// panicStackFrames captures the stack of the panicking goroutine from within a deferred call to Recover, without the
+// frames of Recover itself and of the runtime's panic handling
+func panicStackFrames() []uintptr {
+	// Skip runtime.Callers, rawStackFrames, panicStackFrames, panicError and Recover
+	frames := rawStackFrames(5)
+	for len(frames) > 0 {
+		fn := runtime.FuncForPC(frames[0] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+			break
+		}
+		frames = frames[1:]
+	}
+	return frames
+}
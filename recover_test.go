@@ -0,0 +1,81 @@
+package simplerr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// panics calls panic with the value
+func panics(v interface{}) {
+	panic(v)
+}
+
+// writesNilMap causes a runtime error panic
+func writesNilMap() {
+	var m map[string]int
+	m["key"] = 1
+}
+
+// recovered calls fn and returns the error created from its panic
+func recovered(fn func()) (err error) {
+	defer Recover(&err)
+	fn()
+	return nil
+}
+
+func (s *TestSuite) TestRecover() {
+	s.Run("panic value", func() {
+		err := recovered(func() { panics("boom") })
+		s.EqualError(err, "panic: boom")
+		s.ErrorIs(err, ErrPanic)
+		s.Equal(CodeInternal, GetCode(err))
+		s.Equal("INTERNAL", GetCode(err).String())
+		s.Equal("boom", ExtractAuxiliary(err)["panic"])
+
+		stack := As(err).StackTrace()
+		s.Require().NotEmpty(stack)
+		s.Equal("panics", stack[0].FuncName, "the stack starts at the panic site")
+	})
+
+	s.Run("panic error", func() {
+		original := errors.New("original")
+		err := recovered(func() { panics(original) })
+		s.EqualError(err, "panic: original")
+		s.ErrorIs(err, original)
+		s.ErrorIs(err, ErrPanic)
+		s.Equal(original, ExtractAuxiliary(err)["panic"])
+	})
+
+	s.Run("runtime error", func() {
+		err := recovered(writesNilMap)
+		s.EqualError(err, "panic: assignment to entry in nil map")
+		stack := As(err).StackTrace()
+		s.Require().NotEmpty(stack)
+		s.Equal("writesNilMap", stack[0].FuncName, "runtime frames are trimmed")
+	})
+
+	s.Run("no panic", func() {
+		s.NoError(recovered(func() {}))
+
+		err := func() (err error) {
+			defer Recover(&err)
+			return ErrPanic.New().Message("not a panic")
+		}()
+		s.EqualError(err, "not a panic")
+	})
+
+	s.Run("abort handler is not recovered", func() {
+		s.PanicsWithValue(http.ErrAbortHandler, func() {
+			_ = recovered(func() { panics(http.ErrAbortHandler) })
+		})
+	})
+
+	s.Run("stack options", func() {
+		StackCapture = StackOptions{Keep: func(code Code, benign bool) bool { return code != CodeInternal }}
+		defer func() { StackCapture = StackOptions{} }()
+		s.Empty(As(recovered(func() { panics("boom") })).StackTrace())
+
+		StackCapture = StackOptions{Disabled: true}
+		s.Empty(As(recovered(func() { panics("boom") })).StackTrace())
+	})
+}
@@ -1,65 +1,351 @@
 package simplerr
 
-import "fmt"
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
 
 var (
-	registry *Registry
+	registry atomic.Pointer[Registry]
+	// validCodeName is the format that the names of error codes must have
+	validCodeName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// Errors returned by the registry when a code or namespace cannot be registered
+var (
+	// ErrRegistryFrozen is returned when registering with a registry after `Registry.Freeze()` has been called
+	ErrRegistryFrozen = Define(CodeConstraintViolated, "registry is frozen")
+	// ErrCodeConflict is returned when a code, name or namespace is already registered or overlaps another namespace
+	ErrCodeConflict = Define(CodeAlreadyExists, "error code conflict")
+	// ErrCodeOutOfRange is returned when a code or namespace range is invalid, reserved or outside its namespace
+	ErrCodeOutOfRange = Define(CodeInvalidArgument, "error code out of range")
+	// ErrInvalidName is returned when the name of a code or namespace is invalid
+	ErrInvalidName = Define(CodeInvalidArgument, "invalid name")
 )
 
 // init initializes the default registry with some convenient defaults
 func init() {
-	registry = NewRegistry()
-	for code, description := range defaultErrorCodes {
-		registry.codeDescriptions[code] = description
+	r := NewRegistry()
+	st := r.load()
+	for code, meta := range defaultErrorCodes {
+		st.codes[code] = meta
+		st.names[meta.Name] = code
 	}
+	registry.Store(r)
 }
 
 // GetRegistry gets the currently set registry
 func GetRegistry() *Registry {
-	return registry
+	return registry.Load()
 }
 
-// SetRegistry sets the default error registry
+// SetRegistry sets the default error registry. It is safe to call concurrently with the use of errors.
 func SetRegistry(r *Registry) {
-	registry = r
+	registry.Store(r)
 }
 
-// Registry is a registry of information on how to handle and serve simple errors
+// CodeMetadata describes an error code and the defaults that apply to errors which are assigned the code
+type CodeMetadata struct {
+	// Name is a stable, symbolic name of the code (eg. NOT_FOUND) which is used to refer to the code in text, such as
+	// logs, JSON or configuration files. Names consist of upper case letters, digits and underscores, must start with a
+	// letter and must be unique within the registry. Codes without a name are referred to by their number.
+	Name string
+	// Namespace is the name of the Namespace that owns the code, if any. It is set when the code is registered.
+	Namespace string
+	// Description is a short description of the error code
+	Description string
+	// Benign marks errors that are assigned the code as benign by default
+	Benign bool
+	// Retriable marks errors that are assigned the code as retriable by default
+	Retriable bool
+	// Silent marks errors that are assigned the code as silent by default
+	Silent bool
+	// Severity is the default log severity of errors with the code
+	Severity Severity
+	// DocURL is a link to documentation about the error code. It is included when the error is formatted with `%+v`,
+	// logged, encoded to JSON and sent by the simplegrpc translator as a `google.rpc.Help` detail.
+	DocURL string
+	// HTTPStatus is the HTTP status that the code translates to when the simplehttp mapping does not contain the code.
+	// Zero means there is no translation.
+	HTTPStatus int
+	// GRPCCode is the gRPC status code (see google.golang.org/grpc/codes) that the code translates to when the
+	// simplegrpc mapping does not contain the code. Zero (codes.OK) means there is no translation.
+	GRPCCode uint32
+}
+
+// Registry is a registry of information on how to handle and serve simple errors.
+// A Registry is safe for concurrent use. Lookups never block: every change to the registry publishes a new, immutable
+// copy of its state, so they are meant to happen on startup, after which the registry can be frozen with `Freeze()`.
+// The zero value is equivalent to the registry returned by `NewRegistry()`.
 type Registry struct {
-	codeDescriptions map[Code]string
+	// mu serializes changes to the registry
+	mu    sync.Mutex
+	state atomic.Pointer[registryState]
+}
+
+// registryState is an immutable snapshot of the contents of a Registry
+type registryState struct {
+	codes map[Code]CodeMetadata
+	names map[string]Code
+	// namespaces are the reserved code ranges, sorted by the start of their range
+	namespaces []*Namespace
+	// classifiers are consulted in order to classify errors without a code
+	classifiers []Classifier
+	// formatter overrides the package-level Formatter when set
+	formatter ErrorFormatter
+	// stackOptions overrides the package-level StackCapture options when set
+	stackOptions *StackOptions
+	// catalog is used to translate client-facing messages
+	catalog Catalog
+	frozen  bool
 }
 
-// NewRegistry creates a new registry without any defaults
+// emptyRegistryState is the state of a zero-value Registry which has not been changed yet
+var emptyRegistryState = &registryState{classifiers: DefaultClassifiers()}
+
+// clone returns a copy of the state which can be modified
+func (st *registryState) clone() *registryState {
+	cp := *st
+	cp.codes = maps.Clone(st.codes)
+	if cp.codes == nil {
+		cp.codes = map[Code]CodeMetadata{}
+	}
+	cp.names = maps.Clone(st.names)
+	if cp.names == nil {
+		cp.names = map[string]Code{}
+	}
+	cp.namespaces = slices.Clone(st.namespaces)
+	cp.classifiers = slices.Clone(st.classifiers)
+	return &cp
+}
+
+// NewRegistry creates a new registry without any error codes. It has the DefaultClassifiers so that errors from the
+// standard library, such as `context.Canceled`, are classified no matter which registry is in use.
 func NewRegistry() *Registry {
-	return &Registry{
-		codeDescriptions: map[Code]string{},
+	r := &Registry{}
+	r.state.Store(&registryState{
+		codes:       map[Code]CodeMetadata{},
+		names:       map[string]Code{},
+		classifiers: DefaultClassifiers(),
+	})
+	return r
+}
+
+// load returns the current state of the registry
+func (r *Registry) load() *registryState {
+	if st := r.state.Load(); st != nil {
+		return st
 	}
+	return emptyRegistryState
+}
+
+// update applies the change to a copy of the registry's state and publishes the copy if the change succeeds
+func (r *Registry) update(change func(st *registryState) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st := r.load().clone()
+	if err := change(st); err != nil {
+		return err
+	}
+	r.state.Store(st)
+	return nil
+}
+
+// Freeze prevents any further codes, namespaces or classifiers from being registered. Registration attempts after the registry is
+// frozen fail with ErrRegistryFrozen. The formatter, stack options and catalog can still be changed.
+func (r *Registry) Freeze() {
+	_ = r.update(func(st *registryState) error {
+		st.frozen = true
+		return nil
+	})
+}
+
+// Frozen returns true if the registry has been frozen
+func (r *Registry) Frozen() bool {
+	return r.load().frozen
 }
 
 // RegisterErrorCode registers custom error codes in the registry. This call will panic if the error code is already registered.
 // Error codes 0-99 are reserved for simplerr.
 // This method should be called early on application startup.
 func (r *Registry) RegisterErrorCode(code Code, description string) {
-	if _, exists := r.codeDescriptions[code]; exists {
-		panic("error code %s:%d already registered")
+	r.RegisterCode(code, CodeMetadata{Description: description})
+}
+
+// TryRegisterErrorCode is like RegisterErrorCode but returns an error rather than panicking
+func (r *Registry) TryRegisterErrorCode(code Code, description string) error {
+	return r.TryRegisterCode(code, CodeMetadata{Description: description})
+}
+
+// RegisterCode registers a custom error code in the registry along with its metadata. Errors that are assigned the code
+// with `SimpleError.Code()` inherit the default flags defined in the metadata.
+// This call will panic if the error code is already registered, if the name is invalid or if the name is already in use.
+// Error codes 0-99 are reserved for simplerr and codes in the range of a Namespace must be registered with the namespace.
+// This method should be called early on application startup.
+func (r *Registry) RegisterCode(code Code, meta CodeMetadata) {
+	if err := r.TryRegisterCode(code, meta); err != nil {
+		panic(err)
+	}
+}
+
+// TryRegisterCode is like RegisterCode but returns an error rather than panicking, which is useful when codes are
+// registered by plugins that are loaded at runtime.
+func (r *Registry) TryRegisterCode(code Code, meta CodeMetadata) error {
+	return r.update(func(st *registryState) error {
+		if st.frozen {
+			return Wrapf(ErrRegistryFrozen.New(), "cannot register error code %d", code)
+		}
+		if ns := st.namespaceOf(code); ns != nil {
+			return Wrapf(ErrCodeOutOfRange.New(),
+				"error code %d is in the range of namespace %q (%d to %d) and must be registered with the namespace",
+				code, ns.name, ns.min, ns.max)
+		}
+		meta.Namespace = ""
+		return st.register(code, meta)
+	})
+}
+
+// register adds the error code to the registry after validating that the code and its name are not in use
+func (st *registryState) register(code Code, meta CodeMetadata) error {
+	if existing, exists := st.codes[code]; exists {
+		if existing.Namespace != "" {
+			return Wrapf(ErrCodeConflict.New(), "error code %d already registered in namespace %q", code, existing.Namespace)
+		}
+		return Wrapf(ErrCodeConflict.New(), "error code %d already registered", code)
 	}
 
 	if code < NumberOfReservedCodes {
-		panic(fmt.Sprintf("SimpleError codes 0 to %d are reserved.", NumberOfReservedCodes-1))
+		return Wrapf(ErrCodeOutOfRange.New(), "SimpleError codes 0 to %d are reserved", NumberOfReservedCodes-1)
 	}
-	r.codeDescriptions[code] = description
+
+	if meta.Name != "" {
+		if !validCodeName.MatchString(meta.Name) {
+			return Wrapf(ErrInvalidName.New(), "invalid error code name %q, names must match %s", meta.Name, validCodeName)
+		}
+		if existing, exists := st.names[meta.Name]; exists {
+			return Wrapf(ErrCodeConflict.New(), "error code name %q already registered to code %d", meta.Name, existing)
+		}
+		st.names[meta.Name] = code
+	}
+	st.codes[code] = meta
+	return nil
+}
+
+// RegisterClassifier registers a Classifier which is used to classify errors that do not have a code.
+// Classifiers registered later take precedence over those registered earlier, including the default classifiers.
+// This call will panic if the registry is frozen.
+func (r *Registry) RegisterClassifier(c Classifier) {
+	if err := r.TryRegisterClassifier(c); err != nil {
+		panic(err)
+	}
+}
+
+// TryRegisterClassifier is like RegisterClassifier but returns an error rather than panicking
+func (r *Registry) TryRegisterClassifier(c Classifier) error {
+	return r.update(func(st *registryState) error {
+		if st.frozen {
+			return Wrapf(ErrRegistryFrozen.New(), "cannot register classifier")
+		}
+		st.classifiers = slices.Insert(st.classifiers, 0, c)
+		return nil
+	})
+}
+
+// Classifiers returns the classifiers of the registry in the order they are consulted
+func (r *Registry) Classifiers() []Classifier {
+	return slices.Clone(r.load().classifiers)
 }
 
 // ErrorCodes returns a copy of the registered error codes and their descriptions
 func (r *Registry) ErrorCodes() map[Code]string {
-	codes := make(map[Code]string, len(defaultErrorCodes))
-	for k, v := range r.codeDescriptions {
-		codes[k] = v
+	st := r.load()
+	codes := make(map[Code]string, len(st.codes))
+	for k, v := range st.codes {
+		codes[k] = v.Description
 	}
 	return codes
 }
 
+// ErrorCodeMetadata returns a copy of the registered error codes and their metadata
+func (r *Registry) ErrorCodeMetadata() map[Code]CodeMetadata {
+	return maps.Clone(r.load().codes)
+}
+
+// SetFormatter sets the ErrorFormatter used to generate error strings while this registry is in use.
+// Setting a nil formatter falls back to the package-level `simplerr.Formatter`.
+func (r *Registry) SetFormatter(f ErrorFormatter) {
+	_ = r.update(func(st *registryState) error {
+		st.formatter = f
+		return nil
+	})
+}
+
+// SetStackOptions sets the options used to capture stack traces while this registry is in use.
+// Setting nil options falls back to the package-level `simplerr.StackCapture` options.
+func (r *Registry) SetStackOptions(opts *StackOptions) {
+	_ = r.update(func(st *registryState) error {
+		st.stackOptions = opts
+		return nil
+	})
+}
+
+// SetCatalog sets the Catalog used to translate the client-facing messages of localized errors while this registry is
+// in use. See `SimpleError.Localized()` and `Translate()`.
+func (r *Registry) SetCatalog(c Catalog) {
+	_ = r.update(func(st *registryState) error {
+		st.catalog = c
+		return nil
+	})
+}
+
+// catalog returns the Catalog set on the registry, if any
+func (r *Registry) catalog() Catalog {
+	return r.load().catalog
+}
+
+// formatter returns the ErrorFormatter set on the registry, if any
+func (r *Registry) formatter() ErrorFormatter {
+	return r.load().formatter
+}
+
+// stackOptions returns the stack options set on the registry, if any
+func (r *Registry) stackOptions() *StackOptions {
+	return r.load().stackOptions
+}
+
 // CodeDescription returns the description of the error code
 func (r *Registry) CodeDescription(c Code) string {
-	return r.codeDescriptions[c]
+	return r.load().codes[c].Description
+}
+
+// CodeName returns the name of the error code, or an empty string if the code is not registered with a name
+func (r *Registry) CodeName(c Code) string {
+	return r.load().codes[c].Name
+}
+
+// Lookup returns the error code registered with the given name
+func (r *Registry) Lookup(name string) (Code, bool) {
+	code, ok := r.load().names[name]
+	return code, ok
+}
+
+// CodeNamespace returns the name of the namespace that owns the error code, or an empty string if the code does not
+// belong to a namespace
+func (r *Registry) CodeNamespace(c Code) string {
+	return r.load().codes[c].Namespace
+}
+
+// CodeDocURL returns the link to documentation about the error code, or an empty string if the code has none
+func (r *Registry) CodeDocURL(c Code) string {
+	return r.load().codes[c].DocURL
+}
+
+// CodeMetadata returns the metadata of the error code and whether the code is registered
+func (r *Registry) CodeMetadata(c Code) (CodeMetadata, bool) {
+	meta, ok := r.load().codes[c]
+	return meta, ok
 }
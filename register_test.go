@@ -0,0 +1,117 @@
+package simplerr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+func (s *TestSuite) TestRegistryFreeze() {
+	r := NewRegistry()
+	billing := r.Namespace("billing", 1000, 1999)
+	s.NoError(r.TryRegisterErrorCode(100, "before freeze"))
+	s.NoError(billing.TryRegisterErrorCode(1000, "before freeze"))
+	s.False(r.Frozen())
+
+	r.Freeze()
+	s.True(r.Frozen())
+
+	err := r.TryRegisterErrorCode(101, "after freeze")
+	s.ErrorIs(err, ErrRegistryFrozen)
+	s.EqualError(err, "cannot register error code 101: registry is frozen")
+	s.True(HasErrorCode(err, CodeConstraintViolated))
+
+	err = r.TryRegisterErrorCode(1001, "after freeze")
+	s.ErrorIs(err, ErrRegistryFrozen, "frozen is reported before any other problem")
+
+	err = billing.TryRegisterErrorCode(1001, "after freeze")
+	s.ErrorIs(err, ErrRegistryFrozen)
+
+	ns, err := r.TryNamespace("shipping", 2000, 2999)
+	s.Nil(ns)
+	s.ErrorIs(err, ErrRegistryFrozen)
+	s.EqualError(err, `cannot register namespace "shipping": registry is frozen`)
+
+	s.PanicsWithError("cannot register error code 102: registry is frozen", func() {
+		r.RegisterErrorCode(102, "after freeze")
+	})
+
+	s.Equal(map[Code]string{100: "before freeze", 1000: "before freeze"}, r.ErrorCodes())
+	s.Len(r.Namespaces(), 1)
+
+	// The formatter and stack options can still be changed
+	r.SetFormatter(MessageOnlyFormatter)
+	r.SetStackOptions(&StackOptions{Disabled: true})
+	s.NotNil(r.formatter())
+	s.True(r.stackOptions().Disabled)
+}
+
+func (s *TestSuite) TestRegistryConcurrency() {
+	r := NewRegistry()
+	ns := r.Namespace("plugins", 1000, 1999)
+	defaultRegistry := GetRegistry()
+	defer SetRegistry(defaultRegistry)
+
+	var wg sync.WaitGroup
+	for ii := 0; ii < 10; ii++ {
+		wg.Add(3)
+		// Register codes while others are being looked up and the registry is being swapped
+		go func() {
+			defer wg.Done()
+			s.NoError(r.TryRegisterCode(Code(100+ii), CodeMetadata{Name: fmt.Sprintf("CODE_%d", ii), Retriable: true}))
+			s.NoError(ns.TryRegisterErrorCode(Code(1000+ii), "plugin"))
+		}()
+		go func() {
+			defer wg.Done()
+			serr := New("something").Code(Code(100 + ii))
+			_ = serr.Error()
+			_ = serr.GetDescription()
+			_ = Code(1000 + ii).String()
+			_, _ = r.Lookup(fmt.Sprintf("CODE_%d", ii))
+			_ = r.ErrorCodeMetadata()
+		}()
+		go func() {
+			defer wg.Done()
+			SetRegistry(r)
+			_ = GetRegistry().Namespaces()
+			SetRegistry(defaultRegistry)
+		}()
+	}
+	wg.Wait()
+
+	r.Freeze()
+	s.Len(r.ErrorCodes(), 20)
+	for ii := 0; ii < 10; ii++ {
+		code, ok := r.Lookup(fmt.Sprintf("CODE_%d", ii))
+		s.True(ok)
+		s.Equal(Code(100+ii), code)
+	}
+}
+
+func (s *TestSuite) TestZeroValueRegistry() {
+	r := &Registry{}
+	s.Equal("", r.CodeDescription(CodeNotFound))
+	s.Equal("", r.CodeName(CodeNotFound))
+	s.Equal("", r.CodeNamespace(CodeNotFound))
+	_, ok := r.Lookup("NOT_FOUND")
+	s.False(ok)
+	_, ok = r.CodeMetadata(CodeNotFound)
+	s.False(ok)
+	s.Empty(r.ErrorCodes())
+	s.Empty(r.ErrorCodeMetadata())
+	s.Len(r.Classifiers(), len(DefaultClassifiers()))
+	s.Empty(r.Namespaces())
+	s.False(r.Frozen())
+
+	r.RegisterCode(100, CodeMetadata{Name: "CUSTOM", Description: "custom"})
+	s.Equal("custom", r.CodeDescription(100))
+	code, ok := r.Lookup("CUSTOM")
+	s.True(ok)
+	s.Equal(Code(100), code)
+
+	defaultRegistry := GetRegistry()
+	SetRegistry(&Registry{})
+	defer SetRegistry(defaultRegistry)
+	s.Equal("something", New("something").Code(CodeNotFound).Error())
+	s.True(HasErrorCode(context.Canceled, CodeCanceled))
+}
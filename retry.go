@@ -0,0 +1,167 @@
+package simplerr
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// attrKey is the type of the attribute keys used by simplerr itself
+type attrKey int
+
+const (
+	// attrRetryHistory is the attribute key for the RetryHistory attached to errors returned by Retry
+	attrRetryHistory = attrKey(1)
+)
+
+// RetryPolicy configures how Retry retries an operation and how long it waits between attempts.
+// The delay between attempts grows exponentially from InitialBackoff by a factor of Multiplier, is randomized by Jitter
+// and is then capped at MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the operation is attempted, including the first attempt.
+	// Zero or less means the operation is attempted until it succeeds, fails with an error that should not be
+	// retried or the context is done.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including jitter. Zero means the delay is not capped.
+	MaxBackoff time.Duration
+	// Multiplier is the factor by which the delay grows after each attempt. Values less than or equal to 1 result in
+	// a constant delay.
+	Multiplier float64
+	// Jitter randomizes the delay by up to the given fraction (0 to 1) in either direction to avoid many callers
+	// retrying in lockstep. For example, a jitter of 0.2 results in a delay between 80% and 120% of the backoff.
+	Jitter float64
+	// RetryCodes are the error codes that are retried even if the error is not marked as retriable
+	RetryCodes []Code
+}
+
+// DefaultRetryPolicy returns a retry policy which makes up to 5 attempts with an exponential, jittered backoff
+// starting at 100ms. Errors marked as retriable and errors with CodeUnavailable or CodeResourceExhausted are retried.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryCodes:     []Code{CodeUnavailable, CodeResourceExhausted},
+	}
+}
+
+// ShouldRetry returns true if the error should be retried, meaning it is marked as retriable or has one of the
+// codes in RetryCodes in its chain.
+func (p RetryPolicy) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsRetriable(err) {
+		return true
+	}
+	_, ok := HasErrorCodes(err, p.RetryCodes...)
+	return ok
+}
+
+// Backoff returns the delay to wait after the given (1-indexed) attempt, before the next attempt is made
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	if p.Multiplier > 1 {
+		delay *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	return time.Duration(delay)
+}
+
+// RetryHistory is the history of the attempts made by Retry
+type RetryHistory struct {
+	// Attempts is the number of attempts that were made
+	Attempts int
+	// Codes are the error codes of each failed attempt, in order
+	Codes []Code
+	// Elapsed is the total time spent, including the time waiting between attempts
+	Elapsed time.Duration
+}
+
+// GetRetryHistory gets the history of the attempts attached to an error returned by Retry
+func GetRetryHistory(err error) (*RetryHistory, bool) {
+	v, ok := GetAttribute(err, attrRetryHistory)
+	if !ok {
+		return nil, false
+	}
+	history, ok := v.(*RetryHistory)
+	return history, ok
+}
+
+// GetRetryAfter gets the first retry-after hint in the error chain. See `SimpleError.RetryAfter()`.
+func GetRetryAfter(err error) (time.Duration, bool) {
+	type RetryAfterError interface {
+		GetRetryAfter() (time.Duration, bool)
+	}
+	var delay time.Duration
+	found := walk(err, func(e error) bool {
+		retryAfterErr, ok := e.(RetryAfterError)
+		if !ok {
+			return false
+		}
+		delay, ok = retryAfterErr.GetRetryAfter()
+		return ok
+	})
+	return delay, found
+}
+
+// Retry calls fn until it succeeds or returns an error that should not be retried according to the policy
+// (see `RetryPolicy.ShouldRetry()`). Between attempts, Retry waits for the delay given by the policy's backoff,
+// unless the error carries a retry-after hint (see `SimpleError.RetryAfter()`), in which case the hint is used instead.
+//
+// Retry gives up when the maximum number of attempts is reached, when the context is done, or when waiting for the
+// next attempt would exceed the context's deadline. The returned error wraps the error of the last attempt and
+// records the history of the attempts, which can be retrieved with `GetRetryHistory()`.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	history := &RetryHistory{}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		history.Attempts = attempt
+		history.Codes = append(history.Codes, codeOf(err))
+
+		if !policy.ShouldRetry(err) || (policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts) {
+			return retryError(err, history, start, "")
+		}
+
+		delay, ok := GetRetryAfter(err)
+		if !ok {
+			delay = policy.Backoff(attempt)
+		}
+
+		// Do not bother waiting if the next attempt cannot be made before the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return retryError(err, history, start, "retry aborted: deadline would be exceeded")
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retryError(err, history, start, "retry aborted: "+ctx.Err().Error())
+		case <-timer.C:
+		}
+	}
+}
+
+// retryError wraps the error of the last attempt and attaches the retry history
+func retryError(err error, history *RetryHistory, start time.Time, msg string) *SimpleError {
+	history.Elapsed = time.Since(start)
+	serr := &SimpleError{parent: err, msg: msg, rawStackFrames: wrapStackFrames(err, 4)}
+	return serr.Attr(attrRetryHistory, history).
+		Aux("retry_attempts", history.Attempts, "retry_elapsed", history.Elapsed.String())
+}
@@ -0,0 +1,210 @@
+package simplerr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// fastRetryPolicy is a retry policy with short delays to keep the tests fast
+func fastRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return p
+}
+
+// failingOperation returns an operation that returns the errors in order and then succeeds
+func failingOperation(errs ...error) (func(ctx context.Context) error, *int) {
+	var calls int
+	return func(_ context.Context) error {
+		calls++
+		if calls > len(errs) {
+			return nil
+		}
+		return errs[calls-1]
+	}, &calls
+}
+
+func (s *TestSuite) TestRetry() {
+	ctx := context.Background()
+
+	s.Run("succeeds after retriable errors", func() {
+		fn, calls := failingOperation(
+			New("retriable").Retriable(),
+			New("unavailable").Code(CodeUnavailable),
+			New("exhausted").Code(CodeResourceExhausted),
+		)
+		s.NoError(Retry(ctx, fastRetryPolicy(), fn))
+		s.Equal(4, *calls)
+	})
+
+	s.Run("does not retry non-retriable errors", func() {
+		notFound := New("not found").Code(CodeNotFound)
+		fn, calls := failingOperation(notFound)
+		err := Retry(ctx, fastRetryPolicy(), fn)
+		s.Equal(1, *calls)
+		s.Equal("not found", err.Error())
+		s.ErrorIs(err, notFound)
+
+		history, ok := GetRetryHistory(err)
+		s.Require().True(ok)
+		s.Equal(1, history.Attempts)
+		s.Equal([]Code{CodeNotFound}, history.Codes)
+	})
+
+	s.Run("gives up after max attempts", func() {
+		unavailable := New("unavailable").Code(CodeUnavailable)
+		fn, calls := failingOperation(unavailable, unavailable, unavailable, unavailable)
+		p := fastRetryPolicy()
+		p.MaxAttempts = 3
+
+		start := time.Now()
+		err := Retry(ctx, p, fn)
+		s.Equal(3, *calls)
+		s.True(HasErrorCode(err, CodeUnavailable))
+
+		history, ok := GetRetryHistory(err)
+		s.Require().True(ok)
+		s.Equal(3, history.Attempts)
+		s.Equal([]Code{CodeUnavailable, CodeUnavailable, CodeUnavailable}, history.Codes)
+		s.LessOrEqual(history.Elapsed, time.Since(start))
+		s.Equal(3, ExtractAuxiliary(err)["retry_attempts"])
+		s.Contains(ExtractAuxiliary(err), "retry_elapsed")
+		s.checkCall(As(err).StackTrace()[0], "(*TestSuite).TestRetry.func3")
+	})
+
+	s.Run("custom retry codes", func() {
+		fn, calls := failingOperation(New("unavailable").Code(CodeUnavailable))
+		p := fastRetryPolicy()
+		p.RetryCodes = []Code{CodeNotFound}
+		s.Error(Retry(ctx, p, fn))
+		s.Equal(1, *calls)
+	})
+
+	s.Run("honors retry-after hints", func() {
+		fn, calls := failingOperation(New("slow down").RetryAfter(20 * time.Millisecond))
+		p := fastRetryPolicy()
+		p.InitialBackoff = time.Hour
+
+		start := time.Now()
+		s.NoError(Retry(ctx, p, fn))
+		s.Equal(2, *calls)
+		s.GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+	})
+
+	s.Run("stops when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		unavailable := New("unavailable").Code(CodeUnavailable)
+		fn, calls := failingOperation(unavailable, unavailable)
+		p := fastRetryPolicy()
+		p.InitialBackoff = time.Hour
+		p.MaxBackoff = 0
+
+		time.AfterFunc(10*time.Millisecond, cancel)
+		err := Retry(ctx, p, fn)
+		s.Equal(1, *calls)
+		s.Equal("retry aborted: context canceled: unavailable", err.Error())
+	})
+
+	s.Run("does not wait past the deadline", func() {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		unavailable := New("unavailable").Code(CodeUnavailable)
+		fn, calls := failingOperation(unavailable, unavailable)
+		p := fastRetryPolicy()
+		p.InitialBackoff = time.Hour
+		p.MaxBackoff = 0
+
+		err := Retry(ctx, p, fn)
+		s.Equal(1, *calls)
+		s.Equal("retry aborted: deadline would be exceeded: unavailable", err.Error())
+	})
+
+	s.Run("unlimited attempts", func() {
+		p := fastRetryPolicy()
+		p.MaxAttempts = 0
+		errs := make([]error, 10)
+		for ii := range errs {
+			errs[ii] = fmt.Errorf("wrapped: %w", New("retriable").Retriable())
+		}
+		fn, calls := failingOperation(errs...)
+		s.NoError(Retry(ctx, p, fn))
+		s.Equal(11, *calls)
+	})
+}
+
+func (s *TestSuite) TestRetryPolicy() {
+
+	s.Run("exponential backoff", func() {
+		p := RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, MaxBackoff: 5 * time.Second}
+		s.Equal(time.Second, p.Backoff(1))
+		s.Equal(2*time.Second, p.Backoff(2))
+		s.Equal(4*time.Second, p.Backoff(3))
+		s.Equal(5*time.Second, p.Backoff(4), "backoff is capped")
+	})
+
+	s.Run("constant backoff", func() {
+		p := RetryPolicy{InitialBackoff: time.Second}
+		s.Equal(time.Second, p.Backoff(1))
+		s.Equal(time.Second, p.Backoff(5))
+	})
+
+	s.Run("jittered backoff", func() {
+		p := RetryPolicy{InitialBackoff: time.Second, Jitter: 0.5}
+		for ii := 0; ii < 100; ii++ {
+			d := p.Backoff(1)
+			s.GreaterOrEqual(d, 500*time.Millisecond)
+			s.LessOrEqual(d, 1500*time.Millisecond)
+		}
+	})
+
+	s.Run("jitter does not exceed the max backoff", func() {
+		p := RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, MaxBackoff: 5 * time.Second, Jitter: 0.5}
+		for ii := 0; ii < 100; ii++ {
+			d := p.Backoff(4)
+			s.GreaterOrEqual(d, 4*time.Second)
+			s.LessOrEqual(d, 5*time.Second)
+		}
+	})
+
+	s.Run("should retry", func() {
+		p := DefaultRetryPolicy()
+		s.False(p.ShouldRetry(nil))
+		s.False(p.ShouldRetry(fmt.Errorf("something")))
+		s.False(p.ShouldRetry(New("something").Code(CodeNotFound)))
+		s.True(p.ShouldRetry(New("something").Retriable()))
+		s.True(p.ShouldRetry(Wrap(New("something").Code(CodeUnavailable))))
+	})
+}
+
+func (s *TestSuite) TestRetryAfter() {
+	serr := New("slow down").RetryAfter(time.Second)
+	s.True(serr.GetRetriable(), "setting a retry-after hint marks the error retriable")
+	d, ok := serr.GetRetryAfter()
+	s.True(ok)
+	s.Equal(time.Second, d)
+
+	d, ok = GetRetryAfter(fmt.Errorf("wrapped: %w", Wrap(serr)))
+	s.True(ok)
+	s.Equal(time.Second, d)
+
+	_, ok = GetRetryAfter(New("something").Retriable())
+	s.False(ok)
+
+	_, ok = GetRetryHistory(New("something").Attr(attrRetryHistory, "not a history"))
+	s.False(ok)
+	_, ok = GetRetryHistory(New("something"))
+	s.False(ok)
+
+	s.Contains(fmt.Sprintf("%+v", serr), "retriable: true (retry after 1s)")
+
+	data, err := json.Marshal(serr)
+	s.Require().NoError(err)
+	decoded := &SimpleError{}
+	s.Require().NoError(json.Unmarshal(data, decoded))
+	d, ok = decoded.GetRetryAfter()
+	s.True(ok)
+	s.Equal(time.Second, d)
+}
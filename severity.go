@@ -0,0 +1,117 @@
+package simplerr
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Severity is the severity with which an error should be logged
+type Severity int
+
+const (
+	// SeverityUnset means no severity has been set
+	SeverityUnset Severity = iota
+	// SeverityDebug is for errors that are only useful when debugging
+	SeverityDebug
+	// SeverityInfo is for errors that are expected in normal operation, such as benign errors
+	SeverityInfo
+	// SeverityWarn is for errors that may indicate a problem
+	SeverityWarn
+	// SeverityError is for errors that indicate a problem which needs attention
+	SeverityError
+	// SeverityCritical is for errors that indicate a problem which needs immediate attention
+	SeverityCritical
+)
+
+// LevelCritical is the slog level that SeverityCritical maps to, which is more severe than slog.LevelError
+const LevelCritical = slog.LevelError + 4
+
+// Level returns the slog level that the severity maps to. SeverityUnset maps to slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return LevelCritical
+	default:
+		return slog.LevelError
+	}
+}
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityUnset:
+		return "UNSET"
+	case SeverityDebug:
+		return "DEBUG"
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarn:
+		return "WARN"
+	case SeverityError:
+		return "ERROR"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// GetSeverity gets the severity with which the error should be logged. The severity set on the error nearest to the
+// top of the chain wins. If no error in the chain has a severity, the default severity of the error's code, as defined
+// in the registry's CodeMetadata, is used. Otherwise the severity is SeverityError.
+// SeverityUnset is returned for nil errors.
+func GetSeverity(err error) Severity {
+	if err == nil {
+		return SeverityUnset
+	}
+
+	type severityError interface {
+		GetSeverity() Severity
+	}
+	severity := SeverityUnset
+	walk(err, func(e error) bool {
+		if sevErr, ok := e.(severityError); ok {
+			severity = sevErr.GetSeverity()
+		}
+		return severity != SeverityUnset
+	})
+	if severity != SeverityUnset {
+		return severity
+	}
+
+	if meta, ok := GetRegistry().CodeMetadata(codeOf(err)); ok && meta.Severity != SeverityUnset {
+		return meta.Severity
+	}
+	return SeverityError
+}
+
+// Log logs the error with the logger returned by `SimpleError.GetLogger()`, at the level that the error's severity
+// maps to (see `GetSeverity()`). Silent errors are not logged and benign errors are logged at no more than INFO level,
+// along with their benign reason. Errors that are not SimpleErrors are logged with the default logger.
+// The given attributes, as key-value pairs or `slog.Attr`s like `slog.Logger.Log()`, are added to the log record. This
+// can be used to log details of the request that failed without adding them to the error.
+func Log(ctx context.Context, err error, attrs ...any) {
+	if err == nil || IsSilent(err) {
+		return
+	}
+
+	level := GetSeverity(err).Level()
+	if reason, benign := IsBenign(err); benign {
+		level = min(level, slog.LevelInfo)
+		if reason != "" {
+			attrs = append(attrs, slog.String("benign_reason", reason))
+		}
+	}
+
+	logger := slog.Default()
+	if serr := As(err); serr != nil {
+		logger = serr.GetLogger()
+	}
+	logger.Log(ctx, level, err.Error(), attrs...)
+}
@@ -0,0 +1,124 @@
+package simplerr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+func (s *TestSuite) TestSeverity() {
+	testCases := []struct {
+		severity Severity
+		level    slog.Level
+		name     string
+	}{
+		{SeverityUnset, slog.LevelError, "UNSET"},
+		{SeverityDebug, slog.LevelDebug, "DEBUG"},
+		{SeverityInfo, slog.LevelInfo, "INFO"},
+		{SeverityWarn, slog.LevelWarn, "WARN"},
+		{SeverityError, slog.LevelError, "ERROR"},
+		{SeverityCritical, LevelCritical, "CRITICAL"},
+		{Severity(100), slog.LevelError, "UNKNOWN"},
+	}
+	for _, tc := range testCases {
+		s.Equal(tc.level, tc.severity.Level())
+		s.Equal(tc.name, tc.severity.String())
+	}
+}
+
+func (s *TestSuite) TestGetSeverity() {
+	r := NewRegistry()
+	const CodeNoisy = 100
+	r.RegisterCode(CodeNoisy, CodeMetadata{Description: "noisy", Severity: SeverityDebug})
+	defaultRegistry := GetRegistry()
+	SetRegistry(r)
+	defer SetRegistry(defaultRegistry)
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected Severity
+	}{
+		{"nil error", nil, SeverityUnset},
+		{"non SimpleError", fmt.Errorf("something"), SeverityError},
+		{"no severity", New("something"), SeverityError},
+		{"severity set", New("something").Severity(SeverityWarn), SeverityWarn},
+		{"wrapped severity", Wrap(New("something").Severity(SeverityCritical)), SeverityCritical},
+		{"nearest wins", Wrap(New("something").Severity(SeverityCritical)).Severity(SeverityInfo), SeverityInfo},
+		{"stdlib wrapped", fmt.Errorf("wrapped: %w", New("something").Severity(SeverityWarn)), SeverityWarn},
+		{"joined", errors.Join(New("something"), New("something").Severity(SeverityWarn)), SeverityWarn},
+		{"code default", New("something").Code(CodeNoisy), SeverityDebug},
+		{"wrapped code default", Wrap(New("something").Code(CodeNoisy)), SeverityDebug},
+		{"severity over code default", New("something").Code(CodeNoisy).Severity(SeverityWarn), SeverityWarn},
+		{"template", Define(CodeNoisy, "template").Severity(SeverityCritical).New(), SeverityCritical},
+	}
+	for _, tc := range testCases {
+		s.Equal(tc.expected, GetSeverity(tc.err), tc.name)
+	}
+
+	s.Equal(SeverityUnset, New("something").GetSeverity())
+}
+
+func (s *TestSuite) TestLog() {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	defaultLogger := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(defaultLogger)
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil error", nil, ""},
+		{"default severity", New("something"), "ERROR"},
+		{"debug", New("something").Severity(SeverityDebug), "DEBUG"},
+		{"warn", New("something").Severity(SeverityWarn), "WARN"},
+		{"critical", New("something").Severity(SeverityCritical), "ERROR+4"},
+		{"benign is downgraded", New("something").Benign(), "INFO"},
+		{"benign critical is downgraded", New("something").Benign().Severity(SeverityCritical), "INFO"},
+		{"benign debug is not upgraded", New("something").Benign().Severity(SeverityDebug), "DEBUG"},
+		{"silent is skipped", Wrap(New("something").Silence()).Severity(SeverityCritical), ""},
+		{"non SimpleError", fmt.Errorf("something"), "ERROR"},
+	}
+	for _, tc := range testCases {
+		output.Reset()
+		Log(context.Background(), tc.err)
+		if tc.expected == "" {
+			s.Empty(output.String(), tc.name)
+			continue
+		}
+		got := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(output.Bytes(), &got), tc.name)
+		s.Equal(tc.expected, got["level"], tc.name)
+		s.Equal("something", got["msg"], tc.name)
+	}
+
+	s.Run("uses the logger of the error", func() {
+		output.Reset()
+		var attached bytes.Buffer
+		errLogger := slog.New(slog.NewJSONHandler(&attached, nil))
+		Log(context.Background(), Wrapf(New("inner").Logger(errLogger).Aux("id", 1), "outer").Severity(SeverityWarn))
+		s.Empty(output.String())
+		s.True(strings.Contains(attached.String(), `"level":"WARN","msg":"outer: inner","id":1`), attached.String())
+	})
+
+	s.Run("benign reason", func() {
+		output.Reset()
+		Log(context.Background(), New("something").BenignReason("user typo"))
+		s.Contains(output.String(), `"level":"INFO","msg":"something","benign_reason":"user typo"`)
+	})
+
+	s.Run("additional attributes", func() {
+		output.Reset()
+		serr := New("something").Aux("id", 1).BenignReason("user typo")
+		Log(context.Background(), serr, "request_id", "abc", slog.Int("attempt", 2))
+		s.Contains(output.String(), `"msg":"something","id":1,"request_id":"abc","attempt":2,"benign_reason":"user typo"`)
+		s.Equal(map[string]interface{}{"id": 1}, serr.GetAuxiliary(), "the error is not modified")
+	})
+}
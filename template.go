@@ -0,0 +1,138 @@
+package simplerr
+
+import "maps"
+
+// Template is a reusable definition of an error. Templates are declared once, typically as package-level variables,
+// and are used to create new SimpleError instances which each have their own stack trace and auxiliary data.
+// Every instance created from a template can be detected with `errors.Is(err, template)`.
+//
+//	var ErrUserNotFound = simplerr.Define(simplerr.CodeNotFound, "user not found").Benign()
+//
+//	func GetUser(id int) error {
+//		return ErrUserNotFound.New().Aux("id", id)
+//	}
+//
+// The mutators on a Template modify the template itself and should only be used when declaring the template.
+type Template struct {
+	msg          string
+	publicMsg    string
+	code         Code
+	silent       bool
+	benign       bool
+	benignReason string
+	retriable    bool
+	severity     Severity
+	auxiliary    map[string]interface{}
+}
+
+// Define creates a new error Template with the given code and message.
+func Define(code Code, msg string) *Template {
+	return &Template{code: code, msg: msg}
+}
+
+// Error satisfies the `error` interface so that the template can be used as the target of `errors.Is()`.
+func (t *Template) Error() string {
+	return t.msg
+}
+
+// GetMessage returns the message of errors created from this template
+func (t *Template) GetMessage() string {
+	return t.msg
+}
+
+// GetCode returns the code of errors created from this template
+func (t *Template) GetCode() Code {
+	return t.code
+}
+
+// Public sets the message that is safe to send to clients for errors created from this template
+func (t *Template) Public(msg string) *Template {
+	t.publicMsg = msg
+	return t
+}
+
+// Benign marks errors created from this template as benign
+func (t *Template) Benign() *Template {
+	t.benign = true
+	return t
+}
+
+// BenignReason marks errors created from this template as benign with the given reason
+func (t *Template) BenignReason(reason string) *Template {
+	t.benign = true
+	t.benignReason = reason
+	return t
+}
+
+// Silence marks errors created from this template as silent
+func (t *Template) Silence() *Template {
+	t.silent = true
+	return t
+}
+
+// Retriable marks errors created from this template as retriable
+func (t *Template) Retriable() *Template {
+	t.retriable = true
+	return t
+}
+
+// Severity sets the severity with which errors created from this template are logged
+func (t *Template) Severity(s Severity) *Template {
+	t.severity = s
+	return t
+}
+
+// Aux attaches default auxiliary data to errors created from this template. Each instance receives its own copy.
+// All keys must be of type `string` and have a value. Keys without values are ignored.
+func (t *Template) Aux(kv ...interface{}) *Template {
+	if t.auxiliary == nil {
+		t.auxiliary = map[string]interface{}{}
+	}
+	var key interface{}
+	for _, item := range kv {
+		if key == nil {
+			key = item
+			continue
+		}
+		keyStr, ok := key.(string)
+		if ok {
+			t.auxiliary[keyStr] = item
+		}
+		key = nil
+	}
+	return t
+}
+
+// New creates a new SimpleError from the template. The stack trace is captured at the point New is called.
+func (t *Template) New() *SimpleError {
+	return t.instance(nil)
+}
+
+// Wrap creates a new SimpleError from the template which wraps the given error.
+// The stack trace is captured at the point Wrap is called.
+func (t *Template) Wrap(err error) *SimpleError {
+	return t.instance(err)
+}
+
+// instance creates a new SimpleError from the template
+func (t *Template) instance(parent error) *SimpleError {
+	e := &SimpleError{
+		parent:         parent,
+		msg:            t.msg,
+		publicMsg:      t.publicMsg,
+		code:           t.code,
+		silent:         t.silent,
+		benign:         t.benign,
+		benignReason:   t.benignReason,
+		retriable:      t.retriable,
+		severity:       t.severity,
+		template:       t,
+		rawStackFrames: wrapStackFrames(parent, 4),
+	}
+	if t.auxiliary != nil {
+		e.auxiliary = maps.Clone(t.auxiliary)
+	}
+	e.applyCodeDefaults()
+	e.applyStackPolicy()
+	return e
+}
@@ -0,0 +1,73 @@
+package simplerr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errTestNotFound = Define(CodeNotFound, "user not found").BenignReason("expected").Aux("table", "users")
+	errTestTimeout  = Define(CodeDeadlineExceeded, "timed out").Retriable().Silence()
+)
+
+func newTestNotFound(id int) *SimpleError {
+	return errTestNotFound.New().Aux("id", id)
+}
+
+func (s *TestSuite) TestTemplates() {
+
+	s.Run("instances are matched by errors.Is", func() {
+		err := newTestNotFound(1)
+		s.True(errors.Is(err, errTestNotFound))
+		s.False(errors.Is(err, errTestTimeout))
+		s.True(errors.Is(fmt.Errorf("wrapped: %w", err), errTestNotFound))
+		s.True(errors.Is(Wrapf(err, "wrapped"), errTestNotFound))
+		s.False(errors.Is(New("user not found").Code(CodeNotFound), errTestNotFound))
+		s.False(errors.Is(fmt.Errorf("opaque: %v", err), errTestNotFound))
+	})
+
+	s.Run("instances inherit the template", func() {
+		err := newTestNotFound(1)
+		s.Equal("user not found", err.Error())
+		s.Equal(CodeNotFound, err.GetCode())
+		reason, benign := err.GetBenignReason()
+		s.True(benign)
+		s.Equal("expected", reason)
+		s.False(err.GetSilent())
+		s.False(err.GetRetriable())
+
+		timeout := errTestTimeout.Wrap(fmt.Errorf("i/o timeout"))
+		s.Equal("timed out: i/o timeout", timeout.Error())
+		s.True(timeout.GetSilent())
+		s.True(timeout.GetRetriable())
+		s.Nil(timeout.GetAuxiliary())
+	})
+
+	s.Run("instances do not share state", func() {
+		err1 := newTestNotFound(1)
+		err2 := newTestNotFound(2)
+		s.Equal(map[string]interface{}{"table": "users", "id": 1}, err1.GetAuxiliary())
+		s.Equal(map[string]interface{}{"table": "users", "id": 2}, err2.GetAuxiliary())
+		_ = err2.Code(CodeUnknown).Message("changed")
+
+		s.Equal(CodeNotFound, errTestNotFound.GetCode())
+		s.Equal("user not found", errTestNotFound.GetMessage())
+		s.Equal("user not found", errTestNotFound.Error())
+		s.Equal(CodeNotFound, err1.GetCode())
+	})
+
+	s.Run("instances capture their own stack trace", func() {
+		err := newTestNotFound(1)
+		s.checkCall(err.StackTrace()[0], "newTestNotFound")
+
+		wrapped := errTestTimeout.Wrap(nil)
+		s.checkCall(wrapped.StackTrace()[0], "(*TestSuite).TestTemplates.func4")
+	})
+
+	s.Run("template without benign reason", func() {
+		err := Define(CodeNotFound, "missing").Aux("no_value").Benign().New()
+		_, benign := err.GetBenignReason()
+		s.True(benign)
+		s.Empty(err.GetAuxiliary())
+	})
+}
@@ -3,18 +3,32 @@ package simplerr
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 )
 
-// Wrap wraps the error in a SimpleError. It defaults the error code to CodeUnknown.
+// Wrap wraps the error in a SimpleError. It defaults the error code to CodeUnknown, unless no error in the tree has a
+// code and the error is recognized by a Classifier (eg. `context.Canceled` or `os.ErrNotExist`), in which case it is
+// given the classified code.
 func Wrap(err error) *SimpleError {
-	return &SimpleError{parent: err, rawStackFrames: rawStackFrames(3)}
+	e := &SimpleError{parent: err, rawStackFrames: wrapStackFrames(err, 3)}
+	return e.classify()
+}
+
+// WrapSkip wraps the error in a SimpleError, skipping `skip` additional calls when capturing the stack trace.
+// This is useful for helper functions that wrap errors, so that the stack trace starts at the caller of the helper.
+// A skip of 0 is equivalent to Wrap().
+func WrapSkip(err error, skip int) *SimpleError {
+	e := &SimpleError{parent: err, rawStackFrames: wrapStackFrames(err, 3+skip)}
+	return e.classify()
 }
 
 // Wrapf returns a new SimpleError by wrapping an error with a formatted message string.
-// It defaults the error code to CodeUnknown
+// Like Wrap(), it defaults the error code to CodeUnknown unless the error is recognized by a Classifier.
 func Wrapf(err error, msg string, a ...interface{}) *SimpleError {
 	msg = fmt.Sprintf(msg, a...)
-	return &SimpleError{parent: err, msg: msg, rawStackFrames: rawStackFrames(3)}
+	e := &SimpleError{parent: err, msg: msg, rawStackFrames: wrapStackFrames(err, 3)}
+	return e.classify()
 }
 
 // As attempts to find a SimpleError in the chain of errors, similar to errors.As().
@@ -27,116 +41,210 @@ func As(err error) *SimpleError {
 	return expecterErr
 }
 
-// HasErrorCode checks the error code of an error if it is a SimpleError{}.
-// nil errors or errors that are not SimplErrors return false.
-func HasErrorCode(err error, code Code) bool {
-	type CodedError interface {
-		GetCode() Code
+// Join returns a SimpleError that wraps the given errors, similar to `errors.Join()`. Nil errors are discarded and
+// Join returns nil if every error is nil. The returned SimpleError can carry its own code, flags and auxiliary data.
+// The error string of the joined errors are separated by new lines.
+//
+// Every method of SimpleError can be called on the nil returned by Join, so the result can be chained without a nil
+// check, eg. `simplerr.Join(errA, errB).Code(simplerr.CodeUnavailable)` is nil when errA and errB are nil. Note that,
+// unlike `errors.Join()`, this nil is a nil *SimpleError. Returning it directly from a function whose result is an
+// `error` produces a non-nil error interface, so check the result before returning it:
+//
+//	if err := simplerr.Join(errA, errB); err != nil {
+//		return err
+//	}
+//	return nil
+func Join(errs ...error) *SimpleError {
+	joined := errors.Join(errs...)
+	if joined == nil {
+		return nil
 	}
+	return &SimpleError{parent: joined, rawStackFrames: wrapStackFrames(joined, 3)}
+}
+
+// walk traverses the tree of errors depth-first, calling visit on each error before the errors that it wraps.
+// Errors that wrap multiple errors (eg. `errors.Join()`) have each of their wrapped errors traversed in order.
+// The traversal stops as soon as visit returns true, in which case walk also returns true.
+//
+// This defines the precedence used by all the functions that inspect the error chain: an error takes precedence over
+// the errors it wraps, and errors joined earlier take precedence over errors joined later.
+func walk(err error, visit func(error) bool) bool {
 	if err == nil {
 		return false
 	}
-	// The err may wrap another CodedError who's value may be set. Therefore, we only exit if we find
-	// a matching code, otherwise we traverse the remaining error chain
-	codedErr, ok := err.(CodedError)
-	if ok && codedErr.GetCode() == code {
+	if visit(err) {
 		return true
 	}
 
-	return HasErrorCode(errors.Unwrap(err), code)
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return walk(e.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if walk(wrapped, visit) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-// HasErrorCodes looks for the specified error codes in the chain of errors.
-// It returns the first code in the list that is found in the chain and a boolean for whether
-// anything was found.
-func HasErrorCodes(err error, codes ...Code) (Code, bool) {
+// GetCode returns the code of the error, which is the first code in the tree (in depth-first order) that is not
+// CodeUnknown. Errors without a code are classified (see `Classify()`). It returns CodeUnknown if the error has no code
+// or is nil.
+func GetCode(err error) Code {
+	return codeOf(err)
+}
+
+// codeOf returns the first code in the error chain that is not CodeUnknown. If there are none, the code that the error
+// is classified with (see `Classify()`) is returned, and otherwise CodeUnknown.
+func codeOf(err error) Code {
+	if code := explicitCodeOf(err); code != CodeUnknown {
+		return code
+	}
+	code, _ := Classify(err)
+	return code
+}
+
+// explicitCodeOf returns the first code in the error chain that is not CodeUnknown. If there are none, CodeUnknown is
+// returned.
+func explicitCodeOf(err error) Code {
 	type CodedError interface {
 		GetCode() Code
 	}
-	if err == nil {
-		return 0, false
+	code := CodeUnknown
+	walk(err, func(e error) bool {
+		if codedErr, ok := e.(CodedError); ok && codedErr.GetCode() != CodeUnknown {
+			code = codedErr.GetCode()
+			return true
+		}
+		return false
+	})
+	return code
+}
+
+// HasErrorCode checks the error code of an error if it is a SimpleError{}.
+// nil errors or errors that are not SimplErrors return false, unless no error in the tree has a code and the error is
+// classified with the code by a Classifier (eg. `context.Canceled` is classified as CodeCanceled).
+// The entire tree of errors is searched, including errors combined with `errors.Join()` or `simplerr.Join()`.
+func HasErrorCode(err error, code Code) bool {
+	_, ok := HasErrorCodes(err, code)
+	return ok
+}
+
+// HasErrorCodes looks for the specified error codes in the tree of errors.
+// It returns the code of the first error in the tree (in depth-first order) that matches any of the codes and a
+// boolean for whether anything was found. Like HasErrorCode(), errors without a code are classified.
+func HasErrorCodes(err error, codes ...Code) (Code, bool) {
+	type CodedError interface {
+		GetCode() Code
 	}
-	for _, code := range codes {
+	var found Code
+	ok := walk(err, func(e error) bool {
 		// The err may wrap another CodedError who's value may be set. Therefore, we only exit if we find
-		// a matching code, otherwise we traverse the remaining error chain
-		codedErr, ok := err.(CodedError)
-		if ok && codedErr.GetCode() == code {
-			return code, true
+		// a matching code, otherwise we traverse the remaining error tree
+		codedErr, ok := e.(CodedError)
+		if !ok {
+			return false
 		}
+		for _, code := range codes {
+			if codedErr.GetCode() == code {
+				found = code
+				return true
+			}
+		}
+		return false
+	})
+	if ok {
+		return found, true
 	}
 
-	return HasErrorCodes(errors.Unwrap(err), codes...)
+	// Fall back to classifying errors which have not been given a code
+	if code := codeOf(err); code != CodeUnknown && slices.Contains(codes, code) {
+		return code, true
+	}
+	return found, false
 }
 
-// IsBenign checks the error or any error in the chain, is marked as benign.
-// It also returns the reason the error was marked benign. Benign errors should be logged or handled
+// GetPublicMessage gets the message that is safe to send to clients. The public message set on the error nearest to the
+// top of the chain wins (see `SimpleError.Public()`). If no error in the chain has a public message, the description
+// of the error's code is returned along with false.
+func GetPublicMessage(err error) (string, bool) {
+	type PublicError interface {
+		GetPublicMessage() (string, bool)
+	}
+	var msg string
+	found := walk(err, func(e error) bool {
+		publicErr, ok := e.(PublicError)
+		if !ok {
+			return false
+		}
+		msg, ok = publicErr.GetPublicMessage()
+		return ok
+	})
+	if found {
+		return msg, true
+	}
+	return GetRegistry().CodeDescription(codeOf(err)), false
+}
+
+// IsBenign checks the error or any error in the tree, is marked as benign.
+// It also returns the reason of the first (in depth-first order) benign error. Benign errors should be logged or handled
 // less severely than non-benign errors. For example, you may choose to log benign errors at INFO level,
 // rather than ERROR.
 func IsBenign(err error) (string, bool) {
 	type BenignError interface {
 		GetBenignReason() (string, bool)
 	}
-	if err == nil {
-		return "", false
-	}
+	var reason string
 	// The err may wrap another BenignError who's value may be set to true. Therefore, we only exit if the
-	// benign flag is true, otherwise we keep traversing the error chain
-	benignErr, ok := err.(BenignError)
-	if ok {
-		if reason, benign := benignErr.GetBenignReason(); benign {
-			return reason, true
+	// benign flag is true, otherwise we keep traversing the error tree
+	benign := walk(err, func(e error) bool {
+		benignErr, ok := e.(BenignError)
+		if !ok {
+			return false
 		}
-	}
-
-	return IsBenign(errors.Unwrap(err))
+		r, benign := benignErr.GetBenignReason()
+		if benign {
+			reason = r
+		}
+		return benign
+	})
+	return reason, benign
 }
 
-// IsSilent checks the error or any error in the chain, is marked silent.
+// IsSilent checks the error or any error in the tree, is marked silent.
 // Silent errors should not need to be logged at all.
 func IsSilent(err error) bool {
 	type SilencedError interface {
 		GetSilent() bool
 	}
-	if err == nil {
-		return false
-	}
 	// The err may wrap another SilencedError who's value may be set to true. Therefore, we only exit if the
-	// silent flag is true, otherwise we keep traversing the error chain
-	silenterr, ok := err.(SilencedError)
-	if ok && silenterr.GetSilent() {
-		return true
-	}
-
-	return IsSilent(errors.Unwrap(err))
+	// silent flag is true, otherwise we keep traversing the error tree
+	return walk(err, func(e error) bool {
+		silentErr, ok := e.(SilencedError)
+		return ok && silentErr.GetSilent()
+	})
 }
 
-// IsRetriable checks the error or any error in the chain is retriable, meaning the caller should retry the operation
+// IsRetriable checks the error or any error in the tree is retriable, meaning the caller should retry the operation
 // which caused this error in hopes of it succeeding.
-// Errors are assumed not retriable by default unless an error in the chain says otherwise. A single error in the chain
+// Errors are assumed not retriable by default unless an error in the tree says otherwise. A single error in the tree
 // that is retriable will make the entire error retriable.
 func IsRetriable(err error) bool {
 	type RetriableError interface {
 		GetRetriable() bool
 	}
-
-	// If there is no error, then it is not retriable
-	if err == nil {
-		return false
-	}
-
 	// The error may wrap another RetriableError which may not be retriable. Therefore, we only exit if the
-	// error is not retriable, otherwise we keep traversing the error chain
-	retriableErr, ok := err.(RetriableError)
-	if ok && retriableErr.GetRetriable() {
-		return true
-	}
-
-	// Check errors further in the chain
-	return IsRetriable(errors.Unwrap(err))
+	// error is retriable, otherwise we keep traversing the error tree
+	return walk(err, func(e error) bool {
+		retriableErr, ok := e.(RetriableError)
+		return ok && retriableErr.GetRetriable()
+	})
 }
 
-// ExtractAuxiliary extracts a superset of auxiliary data from all errors in the chain.
-// Wrapper error auxiliary data take precedent over later errors.
+// ExtractAuxiliary extracts a superset of auxiliary data from all errors in the tree.
+// Wrapper error auxiliary data take precedent over later errors, as defined by the depth-first traversal order.
 func ExtractAuxiliary(err error) map[string]interface{} {
 	type AuxHolder interface {
 		GetAuxiliary() map[string]interface{}
@@ -146,21 +254,22 @@ func ExtractAuxiliary(err error) map[string]interface{} {
 	}
 	aux := map[string]interface{}{}
 
-	e := err
-	for e != nil {
+	walk(err, func(e error) bool {
 		if auxHolder, ok := e.(AuxHolder); ok {
 			for k, v := range auxHolder.GetAuxiliary() {
-				aux[k] = v
+				// Errors visited first take precedence, so do not overwrite keys that already exist
+				if _, exists := aux[k]; !exists {
+					aux[k] = v
+				}
 			}
 		}
-
-		e = errors.Unwrap(e)
-	}
+		return false
+	})
 
 	return aux
 }
 
-// GetAttribute gets the first instance of the key in the error chain.
+// GetAttribute gets the first instance of the key in the error tree, in depth-first order.
 // This can be used to define attributes on the error that do not have first-class support
 // with simplerr. Much like keys in the `context` package, the `key` should be a custom type so it does
 // not have naming collisions with other values.
@@ -168,20 +277,24 @@ func GetAttribute(err error, key interface{}) (interface{}, bool) {
 	type AttrHolder interface {
 		GetAttribute(key interface{}) (interface{}, bool)
 	}
-	if err == nil {
-		return nil, false
-	}
-	e := err
-	for e != nil {
-		var attr interface{}
-		if attrHolder, ok := e.(AttrHolder); ok {
-			if attr, ok = attrHolder.GetAttribute(key); ok {
-				return attr, true
-			}
+	var attr interface{}
+	found := walk(err, func(e error) bool {
+		attrHolder, ok := e.(AttrHolder)
+		if !ok {
+			return false
 		}
+		attr, ok = attrHolder.GetAttribute(key)
+		return ok
+	})
+	return attr, found
+}
 
-		e = errors.Unwrap(e)
+// sortedKeys returns the keys of the map in sorted order so that output generated from it is deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
-
-	return nil, false
+	sort.Strings(keys)
+	return keys
 }
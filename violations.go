@@ -0,0 +1,43 @@
+package simplerr
+
+// FieldViolation describes why a single field of a request is invalid
+type FieldViolation struct {
+	// Field is the path to the invalid field (eg. "email" or "address.zip_code")
+	Field string `json:"field"`
+	// Description explains why the field is invalid
+	Description string `json:"description"`
+}
+
+// Invalid creates a new SimpleError with CodeInvalidArgument to which field violations can be added with
+// `SimpleError.Field()`.
+//
+//	return simplerr.Invalid().Field("email", "must be a valid address").Field("age", "must be >= 18")
+func Invalid() *SimpleError {
+	return NewSkip(1, "invalid argument").Code(CodeInvalidArgument)
+}
+
+// GetFieldViolations returns the field violations of every error in the tree, in depth-first order, so that
+// violations added at different layers of the application are merged. Duplicate violations are only returned once.
+// It returns nil if there are none.
+func GetFieldViolations(err error) []FieldViolation {
+	type ViolationError interface {
+		GetFieldViolations() []FieldViolation
+	}
+
+	var violations []FieldViolation
+	seen := map[FieldViolation]bool{}
+	walk(err, func(e error) bool {
+		violationErr, ok := e.(ViolationError)
+		if !ok {
+			return false
+		}
+		for _, v := range violationErr.GetFieldViolations() {
+			if !seen[v] {
+				seen[v] = true
+				violations = append(violations, v)
+			}
+		}
+		return false
+	})
+	return violations
+}
@@ -0,0 +1,74 @@
+package simplerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+func (s *TestSuite) TestFieldViolations() {
+	s.Run("invalid", func() {
+		serr := Invalid().Field("email", "must be a valid address").Field("age", "must be at least 18")
+		s.Equal("invalid argument", serr.Error())
+		s.Equal(CodeInvalidArgument, serr.GetCode())
+		s.Equal([]FieldViolation{
+			{Field: "email", Description: "must be a valid address"},
+			{Field: "age", Description: "must be at least 18"},
+		}, serr.GetFieldViolations())
+		s.checkCall(serr.StackTrace()[0], "(*TestSuite).TestFieldViolations.func1")
+	})
+
+	s.Run("errors without a code become invalid arguments", func() {
+		s.Equal(CodeInvalidArgument, New("bad request").Field("name", "is required").GetCode())
+		s.Equal(CodeMissingParameter, New("bad request").Code(CodeMissingParameter).Field("name", "is required").GetCode(),
+			"existing codes are kept")
+	})
+
+	s.Run("violations are merged across the tree", func() {
+		inner := Invalid().Field("email", "must be a valid address")
+		other := Invalid().Field("age", "must be at least 18")
+		err := Wrapf(errors.Join(fmt.Errorf("stdlib: %w", inner), other, inner), "create user").Field("name", "is required")
+		s.Equal([]FieldViolation{
+			{Field: "name", Description: "is required"},
+			{Field: "email", Description: "must be a valid address"},
+			{Field: "age", Description: "must be at least 18"},
+		}, GetFieldViolations(err))
+
+		s.Nil(GetFieldViolations(New("no violations")))
+		s.Nil(GetFieldViolations(nil))
+	})
+
+	s.Run("clones and frozen errors do not share violations", func() {
+		frozen := Invalid().Field("email", "must be a valid address").Freeze()
+		modified := frozen.Field("age", "must be at least 18")
+		clone := frozen.Clone().Field("name", "is required")
+		s.Len(frozen.GetFieldViolations(), 1)
+		s.Len(modified.GetFieldViolations(), 2)
+		s.Equal("name", clone.GetFieldViolations()[1].Field)
+		s.Equal("age", modified.GetFieldViolations()[1].Field)
+	})
+
+	s.Run("encoding", func() {
+		serr := Invalid().Field("email", "must be a valid address").Field("age", "must be at least 18")
+		s.Contains(fmt.Sprintf("%+v", serr),
+			"\n    field violation: email (must be a valid address)\n    field violation: age (must be at least 18)\n")
+
+		data, err := json.Marshal(serr)
+		s.NoError(err)
+		s.Contains(string(data), `"field_violations":[{"field":"email","description":"must be a valid address"},{"field":"age","description":"must be at least 18"}]`)
+
+		var decoded SimpleError
+		s.NoError(json.Unmarshal(data, &decoded))
+		s.Equal(serr.GetFieldViolations(), GetFieldViolations(&decoded))
+
+		var violations []string
+		for _, attr := range serr.LogValue().Group() {
+			if attr.Key == "field_violations" {
+				for _, v := range attr.Value.Group() {
+					violations = append(violations, v.String())
+				}
+			}
+		}
+		s.Equal([]string{"email=must be a valid address", "age=must be at least 18"}, violations)
+	})
+}